@@ -0,0 +1,122 @@
+package goverhaul
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+
+	"github.com/spf13/afero"
+)
+
+// baselineVersion is the format version of the baseline files written by WriteBaseline
+const baselineVersion = 1
+
+// Baseline is a snapshot of accepted violations. Violations matching a baseline
+// entry are not reported, so that goverhaul can be adopted on code bases with
+// existing violations while still failing on new ones.
+type Baseline struct {
+	Version int             `json:"version"`
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry identifies an accepted violation. Entries are keyed by file, import and
+// rule rather than by position, so they survive unrelated edits of the file.
+type BaselineEntry struct {
+	File   string `json:"file"`
+	Import string `json:"import"`
+	Rule   string `json:"rule"` // The RuleID of the violation, or its Rule when it has no RuleID
+}
+
+// baselineEntryOf returns the baseline entry matching a violation
+func baselineEntryOf(v LintViolation) BaselineEntry {
+	return BaselineEntry{
+		File:   NormalizePath(v.File),
+		Import: v.Import,
+		Rule:   cmp.Or(v.RuleID, v.Rule),
+	}
+}
+
+// NewBaseline creates a baseline accepting the given violations
+func NewBaseline(lv *LintViolations) *Baseline {
+	baseline := &Baseline{
+		Version: baselineVersion,
+		Entries: make([]BaselineEntry, 0, len(lv.Violations)),
+	}
+	for _, v := range lv.Violations {
+		baseline.Entries = append(baseline.Entries, baselineEntryOf(v))
+	}
+
+	slices.SortFunc(baseline.Entries, compareBaselineEntries)
+	baseline.Entries = slices.Compact(baseline.Entries)
+	return baseline
+}
+
+// compareBaselineEntries orders baseline entries by file, import and rule
+func compareBaselineEntries(a, b BaselineEntry) int {
+	return cmp.Or(
+		cmp.Compare(a.File, b.File),
+		cmp.Compare(a.Import, b.Import),
+		cmp.Compare(a.Rule, b.Rule),
+	)
+}
+
+// LoadBaseline reads a baseline file written by WriteBaseline
+func LoadBaseline(fs afero.Fs, path string) (*Baseline, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, WithDetails(WithFile(NewConfigError("failed to read baseline file", err), path),
+			"Create it with: goverhaul baseline write")
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, WithFile(NewConfigError("failed to parse baseline file", err), path)
+	}
+	if baseline.Version != baselineVersion {
+		return nil, WithDetails(WithFile(NewConfigError("unsupported baseline file version", nil), path),
+			"Regenerate it with: goverhaul baseline write")
+	}
+
+	return &baseline, nil
+}
+
+// WriteBaseline writes a baseline accepting the given violations to path
+func WriteBaseline(fs afero.Fs, path string, lv *LintViolations) error {
+	content, err := json.MarshalIndent(NewBaseline(lv), "", "  ")
+	if err != nil {
+		return NewError("failed to encode baseline", err)
+	}
+
+	if err := afero.WriteFile(fs, path, append(content, '\n'), 0o644); err != nil {
+		return WithFile(NewFSError("failed to write baseline file", err), path)
+	}
+	return nil
+}
+
+// Filter removes the baselined violations. It also returns the baseline entries
+// below lintPath that no longer match any violation: they are fixed and can be removed.
+func (b *Baseline) Filter(lv *LintViolations, lintPath string) (*LintViolations, []BaselineEntry) {
+	matched := make(map[BaselineEntry]bool, len(b.Entries))
+	for _, entry := range b.Entries {
+		matched[entry] = false
+	}
+
+	remaining := NewLintViolations()
+	for _, v := range lv.Violations {
+		entry := baselineEntryOf(v)
+		if _, baselined := matched[entry]; baselined {
+			matched[entry] = true
+			continue
+		}
+		remaining.Add(v)
+	}
+
+	fixed := make([]BaselineEntry, 0)
+	for _, entry := range b.Entries {
+		if !matched[entry] && IsSubPath(lintPath, entry.File) {
+			fixed = append(fixed, entry)
+		}
+	}
+
+	return remaining, fixed
+}
@@ -0,0 +1,137 @@
+package goverhaul
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBaseline(t *testing.T) {
+	lv := NewLintViolations()
+	lv.Add(LintViolation{File: "b.go", Line: 3, Import: "fmt", Rule: "pkg", RuleID: "pkg:allowed"})
+	lv.Add(LintViolation{File: "./a.go", Line: 5, Import: "os", Rule: "pkg", RuleID: "pkg:allowed"})
+	lv.Add(LintViolation{File: "b.go", Line: 9, Import: "fmt", Rule: "pkg", RuleID: "pkg:allowed"})
+	lv.Add(LintViolation{File: "c.go", Import: "example.com/x", Rule: noCyclesRule})
+
+	baseline := NewBaseline(lv)
+
+	assert.Equal(t, baselineVersion, baseline.Version)
+	assert.Equal(t, []BaselineEntry{
+		{File: "a.go", Import: "os", Rule: "pkg:allowed"},
+		{File: "b.go", Import: "fmt", Rule: "pkg:allowed"},
+		{File: "c.go", Import: "example.com/x", Rule: noCyclesRule},
+	}, baseline.Entries)
+}
+
+func TestWriteAndLoadBaseline(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	lv := NewLintViolations()
+	lv.Add(LintViolation{File: "a.go", Line: 5, Import: "os", Rule: "pkg", RuleID: "pkg:allowed"})
+
+	require.NoError(t, WriteBaseline(fs, "baseline.json", lv))
+
+	baseline, err := LoadBaseline(fs, "baseline.json")
+	require.NoError(t, err)
+	assert.Equal(t, NewBaseline(lv), baseline)
+}
+
+func TestLoadBaselineErrors(t *testing.T) {
+	tests := map[string]struct {
+		content string
+	}{
+		"missing file":        {content: ""},
+		"invalid json":        {content: "{"},
+		"unsupported version": {content: `{"version": 99, "entries": []}`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if test.content != "" {
+				require.NoError(t, afero.WriteFile(fs, "baseline.json", []byte(test.content), 0o644))
+			}
+
+			_, err := LoadBaseline(fs, "baseline.json")
+			assert.ErrorIs(t, err, ErrConfig)
+		})
+	}
+}
+
+func TestBaselineFilter(t *testing.T) {
+	baseline := &Baseline{Version: baselineVersion, Entries: []BaselineEntry{
+		{File: "internal/api/api.go", Import: "example.com/internal/db", Rule: "internal/api:prohibited:internal/db"},
+		{File: "internal/api/old.go", Import: "example.com/internal/db", Rule: "internal/api:prohibited:internal/db"},
+		{File: "cmd/main.go", Import: "unsafe", Rule: "cmd:allowed"},
+	}}
+
+	lv := NewLintViolations()
+	// Baselined, even though the import moved to another line
+	lv.Add(LintViolation{File: "internal/api/api.go", Line: 12, Import: "example.com/internal/db", Rule: "internal/api", RuleID: "internal/api:prohibited:internal/db"})
+	// New violation in a baselined file
+	lv.Add(LintViolation{File: "internal/api/api.go", Line: 13, Import: "example.com/internal/cache", Rule: "internal/api", RuleID: "internal/api:prohibited:internal/cache"})
+
+	t.Run("whole tree", func(t *testing.T) {
+		remaining, fixed := baseline.Filter(lv, ".")
+
+		require.Len(t, remaining.Violations, 1)
+		assert.Equal(t, "example.com/internal/cache", remaining.Violations[0].Import)
+		assert.Equal(t, []BaselineEntry{baseline.Entries[1], baseline.Entries[2]}, fixed)
+	})
+
+	t.Run("entries outside of the linted path are not fixed", func(t *testing.T) {
+		_, fixed := baseline.Filter(lv, "internal")
+
+		assert.Equal(t, []BaselineEntry{baseline.Entries[1]}, fixed)
+	})
+}
+
+func TestLintBaseline(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com\n\ngo 1.20\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go",
+		[]byte("package api\n\nimport _ \"example.com/internal/db\"\n"), 0o644))
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules: []Rule{{
+			Path:       "internal/api",
+			Prohibited: []ProhibitedPkg{{Name: "internal/db"}, {Name: "internal/cache"}},
+		}},
+	}
+
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+	violations, err := linter.Lint(".")
+	require.ErrorIs(t, err, ErrLint)
+	require.NoError(t, WriteBaseline(memFs, "baseline.json", violations))
+
+	cfg.Baseline = "baseline.json"
+	linter, err = NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	violations, err = linter.Lint(".")
+	require.NoError(t, err)
+	assert.Empty(t, violations.Violations)
+	assert.Equal(t, 1, linter.Summary().Baselined)
+
+	// A new violation fails the run, the baselined one stays hidden
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go",
+		[]byte("package api\n\nimport (\n\t_ \"example.com/internal/cache\"\n\t_ \"example.com/internal/db\"\n)\n"), 0o644))
+	violations, err = linter.Lint(".")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 1)
+	assert.Equal(t, "example.com/internal/cache", violations.Violations[0].Import)
+
+	// Removing the baselined import reports its entry as fixed
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go", []byte("package api\n"), 0o644))
+	_, err = linter.Lint(".")
+	require.NoError(t, err)
+	assert.Len(t, linter.Summary().FixedBaselineEntries, 1)
+}
+
+func TestNewLinterMissingBaseline(t *testing.T) {
+	_, err := NewLinter(Config{Baseline: "baseline.json"}, nil, afero.NewMemMapFs())
+	assert.ErrorIs(t, err, ErrConfig)
+}
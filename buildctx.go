@@ -0,0 +1,169 @@
+package goverhaul
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"path"
+	"slices"
+	"strings"
+)
+
+// BuildContext is a target the linted code is built for. When build contexts are configured,
+// each file is only checked under the contexts it is built for, according to its GOOS/GOARCH
+// file name suffixes and its //go:build constraint.
+type BuildContext struct {
+	// Name identifies the context in violations and Rule.Contexts (default: goos/goarch)
+	Name   string   `yaml:"name" mapstructure:"name"`
+	GOOS   string   `yaml:"goos" mapstructure:"goos"`
+	GOARCH string   `yaml:"goarch" mapstructure:"goarch"`
+	Tags   []string `yaml:"tags" mapstructure:"tags"` // Additional build tags, such as "integration" or "cgo"
+}
+
+// knownOS lists the GOOS values recognized in file names, as go/build does
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true, "openbsd": true,
+	"plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+}
+
+// unixOS lists the GOOS values satisfying the "unix" build constraint
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+	"illumos": true, "ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+}
+
+// knownArch lists the GOARCH values recognized in file names, as go/build does
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true, "arm64be": true,
+	"loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true,
+	"mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true, "riscv64": true,
+	"s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+}
+
+// contextName returns the name of a build context
+func (c BuildContext) contextName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.GOOS + "/" + c.GOARCH
+}
+
+// validateBuildContexts checks the build contexts and the contexts the rules refer to
+func validateBuildContexts(contexts []BuildContext, rules []Rule) error {
+	names := make(map[string]bool, len(contexts))
+	for _, c := range contexts {
+		if !knownOS[c.GOOS] {
+			return WithDetails(NewConfigError("unknown goos "+c.GOOS, nil), "Build context: "+c.contextName())
+		}
+		if !knownArch[c.GOARCH] {
+			return WithDetails(NewConfigError("unknown goarch "+c.GOARCH, nil), "Build context: "+c.contextName())
+		}
+		if names[c.contextName()] {
+			return NewConfigError("duplicate build context "+c.contextName(), nil)
+		}
+		names[c.contextName()] = true
+	}
+
+	for _, rule := range rules {
+		for _, name := range rule.Contexts {
+			if !names[name] {
+				return WithDetails(NewConfigError("unknown build context "+name, nil), "Rule: "+ruleName(rule))
+			}
+		}
+	}
+	return nil
+}
+
+// matches reports whether a file is built in the context
+func (c BuildContext) matches(filePath string, expr constraint.Expr) bool {
+	if !c.matchesFileName(path.Base(filePath)) {
+		return false
+	}
+	return expr == nil || expr.Eval(c.hasTag)
+}
+
+// matchesFileName reports whether the GOOS/GOARCH suffixes of a file name, as in
+// "name_linux_amd64.go" or "name_windows_test.go", match the context
+func (c BuildContext) matchesFileName(name string) bool {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".go"), "_test")
+	parts := strings.Split(name, "_")
+	// The first part is never a constraint: "linux.go" builds everywhere
+	parts = parts[1:]
+
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return c.hasOS(parts[n-2]) && parts[n-1] == c.GOARCH
+	}
+	if n >= 1 && knownOS[parts[n-1]] {
+		return c.hasOS(parts[n-1])
+	}
+	if n >= 1 && knownArch[parts[n-1]] {
+		return parts[n-1] == c.GOARCH
+	}
+	return true
+}
+
+// hasOS reports whether the context satisfies a GOOS, including the GOOS values it implies
+func (c BuildContext) hasOS(goos string) bool {
+	switch {
+	case goos == c.GOOS:
+		return true
+	case goos == "linux":
+		return c.GOOS == "android"
+	case goos == "solaris":
+		return c.GOOS == "illumos"
+	case goos == "darwin":
+		return c.GOOS == "ios"
+	default:
+		return false
+	}
+}
+
+// hasTag reports whether a build tag is satisfied in the context. Release tags such as
+// "go1.21" and the "gc" compiler are always satisfied, "cgo" must be listed in Tags.
+func (c BuildContext) hasTag(tag string) bool {
+	switch {
+	case c.hasOS(tag), tag == c.GOARCH, tag == "gc":
+		return true
+	case tag == "unix":
+		return unixOS[c.GOOS]
+	case strings.HasPrefix(tag, "go1."):
+		return true
+	default:
+		return slices.Contains(c.Tags, tag)
+	}
+}
+
+// buildConstraint returns the build constraint of a parsed file, or nil when it has none.
+// Like the go command, only the comments above the package clause are considered, and a
+// //go:build line takes precedence over // +build lines.
+func buildConstraint(file *ast.File) constraint.Expr {
+	var plusBuild []constraint.Expr
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			switch {
+			case constraint.IsGoBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					return expr
+				}
+			case constraint.IsPlusBuild(comment.Text):
+				if expr, err := constraint.Parse(comment.Text); err == nil {
+					plusBuild = append(plusBuild, expr)
+				}
+			}
+		}
+	}
+
+	var expr constraint.Expr
+	for _, e := range plusBuild {
+		if expr == nil {
+			expr = e
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: e}
+		}
+	}
+	return expr
+}
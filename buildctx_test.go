@@ -0,0 +1,178 @@
+package goverhaul
+
+import (
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildContextMatches(t *testing.T) {
+	linux := BuildContext{GOOS: "linux", GOARCH: "amd64"}
+	android := BuildContext{GOOS: "android", GOARCH: "arm64"}
+	windows := BuildContext{Name: "windows-integration", GOOS: "windows", GOARCH: "amd64", Tags: []string{"integration"}}
+
+	tests := map[string]struct {
+		path       string
+		constraint string
+		expected   map[string]bool
+	}{
+		"plain file": {
+			path:     "internal/sys/sys.go",
+			expected: map[string]bool{"linux/amd64": true, "android/arm64": true, "windows-integration": true},
+		},
+		"os suffix": {
+			path:     "internal/sys/sys_windows.go",
+			expected: map[string]bool{"linux/amd64": false, "android/arm64": false, "windows-integration": true},
+		},
+		"os suffix implied by android": {
+			path:     "internal/sys/sys_linux_test.go",
+			expected: map[string]bool{"linux/amd64": true, "android/arm64": true, "windows-integration": false},
+		},
+		"os and arch suffixes": {
+			path:     "internal/sys/sys_linux_arm64.go",
+			expected: map[string]bool{"linux/amd64": false, "android/arm64": true, "windows-integration": false},
+		},
+		"arch suffix": {
+			path:     "internal/sys/sys_amd64.go",
+			expected: map[string]bool{"linux/amd64": true, "android/arm64": false, "windows-integration": true},
+		},
+		"name without constraint prefix": {
+			path:     "internal/sys/linux.go",
+			expected: map[string]bool{"linux/amd64": true, "android/arm64": true, "windows-integration": true},
+		},
+		"unix constraint": {
+			path:       "internal/sys/sys.go",
+			constraint: "//go:build unix",
+			expected:   map[string]bool{"linux/amd64": true, "android/arm64": true, "windows-integration": false},
+		},
+		"custom tag": {
+			path:       "internal/sys/sys.go",
+			constraint: "//go:build integration && !arm64",
+			expected:   map[string]bool{"linux/amd64": false, "android/arm64": false, "windows-integration": true},
+		},
+		"release tag": {
+			path:       "internal/sys/sys.go",
+			constraint: "//go:build go1.21 && linux",
+			expected:   map[string]bool{"linux/amd64": true, "android/arm64": true, "windows-integration": false},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var expr constraint.Expr
+			if test.constraint != "" {
+				var err error
+				expr, err = constraint.Parse(test.constraint)
+				require.NoError(t, err)
+			}
+			for _, context := range []BuildContext{linux, android, windows} {
+				assert.Equal(t, test.expected[context.contextName()], context.matches(test.path, expr), context.contextName())
+			}
+		})
+	}
+}
+
+func TestBuildConstraint(t *testing.T) {
+	tests := map[string]struct {
+		source   string
+		expected string
+	}{
+		"no constraint":      {source: "package sys\n", expected: ""},
+		"go:build":           {source: "//go:build linux && amd64\n\npackage sys\n", expected: "linux && amd64"},
+		"go:build wins":      {source: "// +build windows\n//go:build linux\n\npackage sys\n", expected: "linux"},
+		"plus build lines":   {source: "// +build linux darwin\n// +build amd64\n\npackage sys\n", expected: "(linux || darwin) && amd64"},
+		"after package only": {source: "package sys\n\n//go:build linux\n", expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file, err := parser.ParseFile(token.NewFileSet(), "sys.go", test.source, parser.ImportsOnly|parser.ParseComments)
+			require.NoError(t, err)
+
+			expr := buildConstraint(file)
+			if test.expected == "" {
+				assert.Nil(t, expr)
+				return
+			}
+			require.NotNil(t, expr)
+			assert.Equal(t, test.expected, expr.String())
+		})
+	}
+}
+
+func TestValidateBuildContexts(t *testing.T) {
+	tests := map[string]struct {
+		contexts []BuildContext
+		rules    []Rule
+	}{
+		"unknown goos":      {contexts: []BuildContext{{GOOS: "beos", GOARCH: "amd64"}}},
+		"unknown goarch":    {contexts: []BuildContext{{GOOS: "linux", GOARCH: "z80"}}},
+		"duplicate context": {contexts: []BuildContext{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "linux", GOARCH: "amd64"}}},
+		"unknown rule context": {
+			contexts: []BuildContext{{GOOS: "linux", GOARCH: "amd64"}},
+			rules:    []Rule{{Path: "internal", Contexts: []string{"windows/amd64"}}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, validateBuildContexts(test.contexts, test.rules), ErrConfig)
+		})
+	}
+
+	assert.NoError(t, validateBuildContexts([]BuildContext{{Name: "linux", GOOS: "linux", GOARCH: "amd64"}},
+		[]Rule{{Path: "internal", Contexts: []string{"linux"}}}))
+}
+
+func TestLintBuildContexts(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"internal/platform/platform.go":         "package platform\n\nimport \"github.com/acme/metrics\"\n",
+		"internal/platform/platform_windows.go": "package platform\n\nimport \"golang.org/x/sys/windows\"\n",
+		"internal/platform/platform_linux.go":   "package platform\n\nimport \"golang.org/x/sys/unix\"\n",
+		"internal/platform/plan9.go":            "//go:build plan9\n\npackage platform\n\nimport \"github.com/acme/plan9\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+
+	cfg := Config{
+		Modfile: "go.mod",
+		BuildContexts: []BuildContext{
+			{Name: "linux", GOOS: "linux", GOARCH: "amd64"},
+			{Name: "windows", GOOS: "windows", GOARCH: "amd64"},
+		},
+		Rules: []Rule{
+			{Path: "internal/platform", Prohibited: []ProhibitedPkg{{Name: "github.com/acme/metrics"}}},
+			{Path: "internal/platform", ID: "linux-only", Contexts: []string{"linux"}, Allowed: []string{"@stdlib", "golang.org/x/sys/unix"}},
+		},
+	}
+	linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+
+	type found struct {
+		file, rule string
+		contexts   []string
+	}
+	var actual []found
+	for _, v := range violations.Violations {
+		actual = append(actual, found{v.File, v.RuleID, v.Contexts})
+	}
+	// The windows file is only built for windows, where the linux-only rule does not apply,
+	// and the plan9 file is not built in any context
+	assert.Equal(t, []found{
+		{"internal/platform/platform.go", "internal/platform:prohibited:github.com/acme/metrics", []string{"linux", "windows"}},
+		{"internal/platform/platform.go", "linux-only:allowed", []string{"linux"}},
+	}, actual)
+	assert.Contains(t, violations.PrintByFile(), "Contexts: linux, windows")
+}
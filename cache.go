@@ -1,26 +1,41 @@
 package goverhaul
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/gophersatwork/granular"
 	"github.com/spf13/afero"
 )
 
+// LintCache stores the violations of files for incremental analysis.
+// Entries are keyed on the content of the file, the fingerprint of the configuration
+// and the module the file belongs to. It is safe for concurrent use: granular.Cache is, and
+// the fingerprint is guarded by a lock, so that lookups and stores of files run in parallel.
 type LintCache struct {
-	gCache *granular.Cache
-	fs     afero.Fs
+	gCache  *granular.Cache
+	fs      afero.Fs
+	root    string // The cache directory
+	workDir string // The working directory the cached file paths are relative to
+
+	mu          sync.Mutex        // Guards fingerprint
+	fingerprint map[string]string // Key components shared by all the entries, see SetFingerprint
+	// modulePath returns the module path of a file, to key entries on it. Optional.
+	modulePath func(path string) string
 }
 
+// fingerprintFile is the file of the cache directory holding the fingerprint of its entries
+const fingerprintFile = "fingerprint.json"
+
 func NewCache(path string) (*LintCache, error) {
-	cache, err := granular.New(path)
-	if err != nil {
-		return nil, err
-	}
-	return &LintCache{
-		gCache: cache,
-	}, nil
+	return NewCacheWithFs(path, afero.NewOsFs())
 }
 
 func NewCacheWithFs(path string, fs afero.Fs) (*LintCache, error) {
@@ -29,31 +44,55 @@ func NewCacheWithFs(path string, fs afero.Fs) (*LintCache, error) {
 		return nil, err
 	}
 	return &LintCache{
-		gCache: cache,
-		fs:     fs,
+		gCache:  cache,
+		fs:      fs,
+		root:    path,
+		workDir: AbsPath("."),
 	}, nil
 }
 
-func (c *LintCache) AddFile(path string) error {
-	// Normalize the path for consistent caching
-	normalizedPath := NormalizePath(path)
-	key := granular.Key{
-		Inputs: []granular.Input{granular.FileInput{
-			Path: normalizedPath,
-			Fs:   c.fs,
-		}},
+// SetFingerprint sets the key components shared by all the entries, such as the hash of the
+// rules and the goverhaul version. When they differ from the ones the cache was filled with,
+// the cache is cleared, as none of its entries can match anymore. It reports whether the cache was cleared.
+func (c *LintCache) SetFingerprint(fingerprint map[string]string) (bool, error) {
+	c.useFingerprint(fingerprint)
+	path := JoinPaths(c.root, fingerprintFile)
+
+	var stored map[string]string
+	if content, err := afero.ReadFile(c.fs, path); err == nil {
+		// An unreadable fingerprint is handled like a different one
+		_ = json.Unmarshal(content, &stored)
 	}
-	err := c.gCache.Store(key, granular.Result{})
+	if maps.Equal(stored, fingerprint) {
+		return false, nil
+	}
+
+	if err := c.gCache.Clear(); err != nil {
+		return false, NewCacheError("failed to clear cache", err)
+	}
+	content, err := json.Marshal(fingerprint)
 	if err != nil {
-		return err
+		return false, NewCacheError("failed to encode cache fingerprint", err)
+	}
+	if err := afero.WriteFile(c.fs, path, content, 0o644); err != nil {
+		return false, WithFile(NewCacheError("failed to write cache fingerprint", err), path)
 	}
+	return true, nil
+}
 
-	return nil
+// useFingerprint sets the key components shared by all the entries, leaving the cache as is
+func (c *LintCache) useFingerprint(fingerprint map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fingerprint = fingerprint
 }
 
-func (c *LintCache) AddFileWithViolations(path string, lv []LintViolation) error {
-	// Normalize the path for consistent caching
-	normalizedPath := NormalizePath(path)
+// key returns the cache key of a file
+func (c *LintCache) key(normalizedPath string) granular.Key {
+	c.mu.Lock()
+	// The fingerprint is replaced, never modified, so it can be read once the lock is released
+	fingerprint := c.fingerprint
+	c.mu.Unlock()
 
 	key := granular.Key{
 		Inputs: []granular.Input{granular.FileInput{
@@ -61,62 +100,160 @@ func (c *LintCache) AddFileWithViolations(path string, lv []LintViolation) error
 			Fs:   c.fs,
 		}},
 	}
-
-	metadata := make(map[string]string)
-	// Create a LintViolations struct to hold the violations
-	violations := LintViolations{
-		Violations: lv,
+	if len(fingerprint) > 0 || c.modulePath != nil {
+		key.Extra = maps.Clone(fingerprint)
+		if key.Extra == nil {
+			key.Extra = make(map[string]string)
+		}
+		if c.modulePath != nil {
+			key.Extra["module"] = c.modulePath(normalizedPath)
+		}
 	}
-	lvBytes, err := json.Marshal(violations)
+	return key
+}
+
+// cacheFingerprint returns the fingerprint of the cache entries produced with a configuration:
+// a hash of the settings that affect the violations of a file, and the goverhaul version.
+// In packages analysis, imports resolve against go.mod, so its content is part of the fingerprint.
+// The modules of the go.work workspace are too, as they decide which imports are module imports.
+func cacheFingerprint(cfg Config, fs afero.Fs, workspace []string) (map[string]string, error) {
+	settings := struct {
+		Rules         []Rule
+		Tests         *TestsPolicy
+		BuildContexts []BuildContext
+		Generated     GeneratedPolicy
+		Modfile       string
+		Analysis      AnalysisMode
+	}{cfg.EffectiveRules(), cfg.Tests, cfg.BuildContexts, cfg.Generated, cfg.Modfile, cfg.Analysis}
+
+	content, err := json.Marshal(settings)
 	if err != nil {
-		return err
+		return nil, NewCacheError("failed to encode configuration", err)
+	}
+	sum := sha256.Sum256(content)
+	fingerprint := map[string]string{
+		"rules":   hex.EncodeToString(sum[:]),
+		"version": toolVersion(),
+	}
+	if len(workspace) > 0 {
+		workspace = slices.Sorted(slices.Values(workspace))
+		fingerprint["workspace"] = strings.Join(workspace, "\n")
 	}
 
-	metadata["violations"] = string(lvBytes)
-	res := granular.Result{
-		Metadata: metadata,
+	if cfg.Analysis == AnalysisPackages {
+		if modfile, err := afero.ReadFile(fs, cmp.Or(cfg.Modfile, "go.mod")); err == nil {
+			sum := sha256.Sum256(modfile)
+			fingerprint["modfile"] = hex.EncodeToString(sum[:])
+		}
 	}
+	return fingerprint, nil
+}
 
-	err = c.gCache.Store(key, res)
-	if err != nil {
-		return err
+func (c *LintCache) AddFile(path string) error {
+	return c.addEntry(path, cachedFile{})
+}
+
+func (c *LintCache) AddFileWithViolations(path string, lv []LintViolation) error {
+	return c.addEntry(path, cachedFile{violations: lv})
+}
+
+// cachedFile is the outcome of the linting of a file, as stored in the cache
+type cachedFile struct {
+	violations   []LintViolation
+	suppressions []Suppression // The suppressions applied to the file
+	rules        []string      // The names of the rules checked against the file
+}
+
+// addEntry caches the outcome of the linting of a file. The suppressions and the rules are
+// reported again when the file is served from the cache. The working directory is recorded
+// too, so that cache maintenance finds relative files from any directory.
+func (c *LintCache) addEntry(path string, file cachedFile) error {
+	// Normalize the path for consistent caching
+	normalizedPath := NormalizePath(path)
+
+	metadata := map[string]string{"dir": c.workDir}
+	if len(file.violations) > 0 {
+		// Create a LintViolations struct to hold the violations
+		violations := LintViolations{
+			Violations: file.violations,
+		}
+		lvBytes, err := json.Marshal(violations)
+		if err != nil {
+			return err
+		}
+		metadata["violations"] = string(lvBytes)
+	}
+	if len(file.suppressions) > 0 {
+		sBytes, err := json.Marshal(file.suppressions)
+		if err != nil {
+			return err
+		}
+		metadata["suppressions"] = string(sBytes)
+	}
+	if len(file.rules) > 0 {
+		metadata["rules"] = strings.Join(file.rules, "\n")
 	}
 
-	return nil
+	return c.gCache.Store(c.key(normalizedPath), granular.Result{Metadata: metadata})
 }
 
-var (
-	ErrEntryNotFound           = errors.New("entry not found")
-	ErrReadingCachedViolations = errors.New("cached violations are invalid")
+// CacheStatus is the outcome of the lookup of a file in the cache
+type CacheStatus int
+
+const (
+	// CacheMiss means the file has no entry for its current content and configuration
+	CacheMiss CacheStatus = iota
+	// CacheHitClean means the file is cached without violations
+	CacheHitClean
+	// CacheHitViolations means the file is cached with violations
+	CacheHitViolations
 )
 
-func (c *LintCache) HasEntry(filePath string) (LintViolations, error) {
+// ErrReadingCachedViolations is returned when a cache entry holds invalid violations
+var ErrReadingCachedViolations = errors.New("cached violations are invalid")
+
+// HasEntry looks a file up in the cache. Violations restored from the cache are marked as Cached.
+// An invalid entry is reported as a miss along with ErrReadingCachedViolations.
+func (c *LintCache) HasEntry(filePath string) (CacheStatus, LintViolations, error) {
+	status, file, err := c.lookup(filePath)
+	return status, LintViolations{Violations: file.violations}, err
+}
+
+// lookup is HasEntry returning the whole cached outcome of the file
+func (c *LintCache) lookup(filePath string) (CacheStatus, cachedFile, error) {
 	// Normalize the path for consistent caching
 	normalizedPath := NormalizePath(filePath)
 
-	key := granular.Key{
-		Inputs: []granular.Input{granular.FileInput{
-			Path: normalizedPath,
-			Fs:   c.fs,
-		}},
+	result, found, _ := c.gCache.Get(c.key(normalizedPath))
+	if !found {
+		return CacheMiss, cachedFile{}, nil
 	}
 
-	result, found, _ := c.gCache.Get(key)
-	var err error
-
-	if !found {
-		return LintViolations{}, ErrEntryNotFound
+	var file cachedFile
+	if cached, ok := result.Metadata["suppressions"]; ok {
+		if err := json.Unmarshal([]byte(cached), &file.suppressions); err != nil {
+			return CacheMiss, cachedFile{}, ErrReadingCachedViolations
+		}
+	}
+	if rules, ok := result.Metadata["rules"]; ok {
+		file.rules = strings.Split(rules, "\n")
 	}
 
 	violations, ok := result.Metadata["violations"]
 	if !ok {
-		return LintViolations{}, nil
+		return CacheHitClean, file, nil
 	}
 
 	var lv LintViolations
-	err = json.Unmarshal([]byte(violations), &lv)
-	if err != nil {
-		return LintViolations{}, ErrReadingCachedViolations
+	if err := json.Unmarshal([]byte(violations), &lv); err != nil {
+		return CacheMiss, cachedFile{}, ErrReadingCachedViolations
+	}
+	if len(lv.Violations) == 0 {
+		return CacheHitClean, file, nil
+	}
+	for i := range lv.Violations {
+		lv.Violations[i].Cached = true
 	}
-	return lv, nil
+	file.violations = lv.Violations
+	return CacheHitViolations, file, nil
 }
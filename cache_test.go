@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/gophersatwork/granular"
@@ -101,10 +102,14 @@ func TestLintCache_AddFileWithViolations(t *testing.T) {
 	// Create test violations
 	violations := []LintViolation{
 		{
-			File:   testPath,
-			Import: "prohibited/package",
-			Cause:  "This import is prohibited",
-			Rule:   "test-rule",
+			File:      testPath,
+			Line:      3,
+			Column:    8,
+			EndLine:   3,
+			EndColumn: 28,
+			Import:    "prohibited/package",
+			Cause:     "This import is prohibited",
+			Rule:      "test-rule",
 		},
 	}
 
@@ -149,6 +154,13 @@ func TestLintCache_AddFileWithViolations(t *testing.T) {
 	if cachedViolations.Violations[0].Import != violations[0].Import {
 		t.Errorf("Expected import %s, got %s", violations[0].Import, cachedViolations.Violations[0].Import)
 	}
+
+	if got := cachedViolations.Violations[0].Location(); got != violations[0].Location() {
+		t.Errorf("Expected location %s, got %s", violations[0].Location(), got)
+	}
+	if cachedViolations.Violations[0].EndColumn != violations[0].EndColumn {
+		t.Errorf("Expected end column %d, got %d", violations[0].EndColumn, cachedViolations.Violations[0].EndColumn)
+	}
 }
 
 func TestLintCache_HasEntry(t *testing.T) {
@@ -171,9 +183,12 @@ func TestLintCache_HasEntry(t *testing.T) {
 	}
 
 	t.Run("file not in cache", func(t *testing.T) {
-		_, err = lintCache.HasEntry(testPath)
-		if !errors.Is(err, ErrEntryNotFound) {
-			t.Errorf("Expected ErrEntryNotFound for non-existent file, got %v", err)
+		status, _, err := lintCache.HasEntry(testPath)
+		if err != nil {
+			t.Errorf("Unexpected error checking cache entry: %v", err)
+		}
+		if status != CacheMiss {
+			t.Errorf("Expected CacheMiss for non-existent file, got %v", status)
 		}
 	})
 
@@ -184,10 +199,13 @@ func TestLintCache_HasEntry(t *testing.T) {
 		}
 
 		// Verify file is in cache without violations
-		violations, err := lintCache.HasEntry(testPath)
+		status, violations, err := lintCache.HasEntry(testPath)
 		if err != nil {
 			t.Errorf("Unexpected error checking cache entry: %v", err)
 		}
+		if status != CacheHitClean {
+			t.Errorf("Expected CacheHitClean, got %v", status)
+		}
 		if !violations.IsEmpty() {
 			t.Errorf("Expected empty violations, got %d violations", len(violations.Violations))
 		}
@@ -209,10 +227,13 @@ func TestLintCache_HasEntry(t *testing.T) {
 		}
 
 		// Verify file is in cache with violations
-		violations, err := lintCache.HasEntry(testPath)
+		status, violations, err := lintCache.HasEntry(testPath)
 		if err != nil {
 			t.Errorf("Unexpected error checking cache entry: %v", err)
 		}
+		if status != CacheHitViolations {
+			t.Errorf("Expected CacheHitViolations, got %v", status)
+		}
 		if violations.IsEmpty() {
 			t.Errorf("Expected violations, got empty violations")
 		}
@@ -222,8 +243,8 @@ func TestLintCache_HasEntry(t *testing.T) {
 		if violations.Violations[0].Import != testViolations[0].Import {
 			t.Errorf("Expected import %s, got %s", testViolations[0].Import, violations.Violations[0].Import)
 		}
-		if violations.Violations[0].Cached {
-			t.Errorf("Expected cached %v, got %s", true, violations.Violations[0].Import)
+		if !violations.Violations[0].Cached {
+			t.Errorf("Expected cached %v, got %v", true, violations.Violations[0].Cached)
 		}
 	})
 
@@ -250,7 +271,10 @@ func TestLintCache_HasEntry(t *testing.T) {
 		}
 
 		// Verify error when reading invalid violations
-		_, err = lintCache.HasEntry(testPath)
+		status, _, err := lintCache.HasEntry(testPath)
+		if status != CacheMiss {
+			t.Errorf("Expected CacheMiss for invalid violations, got %v", status)
+		}
 		if !errors.Is(err, ErrReadingCachedViolations) {
 			t.Errorf("Expected ErrReadingCachedViolations for invalid violations, got %v", err)
 		}
@@ -267,3 +291,115 @@ func NewCacheFs(t *testing.T, cacheDir string) afero.Fs {
 	}
 	return memFs
 }
+
+func TestLintCache_SetFingerprint(t *testing.T) {
+	cacheDir := "/tmp/cache"
+	memFs := NewCacheFs(t, cacheDir)
+
+	testPath := filepath.Join(cacheDir, "main.go")
+	err := afero.WriteFile(memFs, testPath, []byte("package main\n\nfunc main() {}\n"), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	open := func(fingerprint map[string]string) (*LintCache, bool) {
+		lintCache, err := NewCacheWithFs(cacheDir, memFs)
+		if err != nil {
+			t.Fatalf("Failed to create granular cache: %v", err)
+		}
+		cleared, err := lintCache.SetFingerprint(fingerprint)
+		if err != nil {
+			t.Fatalf("Failed to set fingerprint: %v", err)
+		}
+		return lintCache, cleared
+	}
+
+	lintCache, _ := open(map[string]string{"rules": "a", "version": "v1"})
+	if err := lintCache.AddFile(testPath); err != nil {
+		t.Fatalf("Failed to add file to cache: %v", err)
+	}
+
+	lintCache, cleared := open(map[string]string{"rules": "a", "version": "v1"})
+	if cleared {
+		t.Error("Expected the cache to be kept for the same fingerprint")
+	}
+	if status, _, _ := lintCache.HasEntry(testPath); status != CacheHitClean {
+		t.Errorf("Expected the entry to be found, got %v", status)
+	}
+
+	lintCache, cleared = open(map[string]string{"rules": "b", "version": "v1"})
+	if !cleared {
+		t.Error("Expected the cache to be cleared when the rules change")
+	}
+	if status, _, _ := lintCache.HasEntry(testPath); status != CacheMiss {
+		t.Errorf("Expected CacheMiss after clearing the cache, got %v", status)
+	}
+
+	_, cleared = open(map[string]string{"rules": "b", "version": "v2"})
+	if !cleared {
+		t.Error("Expected the cache to be cleared when the version changes")
+	}
+}
+
+func TestLintCache_ModuleKey(t *testing.T) {
+	cacheDir := "/tmp/cache"
+	memFs := NewCacheFs(t, cacheDir)
+
+	testPath := filepath.Join(cacheDir, "main.go")
+	err := afero.WriteFile(memFs, testPath, []byte("package main\n\nfunc main() {}\n"), 0o644)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	lintCache, err := NewCacheWithFs(cacheDir, memFs)
+	if err != nil {
+		t.Fatalf("Failed to create granular cache: %v", err)
+	}
+	modulePath := "example.com/old"
+	lintCache.modulePath = func(string) string { return modulePath }
+
+	if err := lintCache.AddFile(testPath); err != nil {
+		t.Fatalf("Failed to add file to cache: %v", err)
+	}
+	if status, _, _ := lintCache.HasEntry(testPath); status != CacheHitClean {
+		t.Errorf("Expected the entry to be found, got %v", status)
+	}
+
+	modulePath = "example.com/new"
+	if status, _, _ := lintCache.HasEntry(testPath); status != CacheMiss {
+		t.Errorf("Expected CacheMiss after the module path changed, got %v", status)
+	}
+}
+
+func TestLintCache_Concurrent(t *testing.T) {
+	cacheDir := "/tmp/cache"
+	memFs := NewCacheFs(t, cacheDir)
+
+	lintCache, err := NewCacheWithFs(cacheDir, memFs)
+	if err != nil {
+		t.Fatalf("Failed to create granular cache: %v", err)
+	}
+	if _, err := lintCache.SetFingerprint(map[string]string{"rules": "hash"}); err != nil {
+		t.Fatalf("Failed to set the fingerprint: %v", err)
+	}
+
+	// Workers store and look up distinct files in parallel
+	var wg sync.WaitGroup
+	for i := range 8 {
+		testPath := filepath.Join(cacheDir, fmt.Sprintf("file%d.go", i))
+		if err := afero.WriteFile(memFs, testPath, []byte("package main\n"), 0o644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := lintCache.AddFile(testPath); err != nil {
+				t.Errorf("Failed to add file to cache: %v", err)
+			}
+			if status, _, _ := lintCache.HasEntry(testPath); status != CacheHitClean {
+				t.Errorf("Expected CacheHitClean for %s, got %v", testPath, status)
+			}
+		}()
+	}
+	wg.Wait()
+}
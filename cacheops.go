@@ -0,0 +1,277 @@
+package goverhaul
+
+import (
+	"cmp"
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gophersatwork/granular"
+	"github.com/spf13/afero"
+)
+
+// lastRunFile is the file of the cache directory holding the cache lookups of the last run
+const lastRunFile = "last_run.json"
+
+// CacheEntry is an entry of the cache: the results of a file for one version of its content
+type CacheEntry struct {
+	KeyHash    string    `json:"key_hash"`
+	File       string    `json:"file"`
+	Violations int       `json:"violations"`
+	CreatedAt  time.Time `json:"created_at"`
+	dir        string    // The working directory File is relative to, empty when unknown
+}
+
+// path returns the path of the file of the entry from the current working directory
+func (e CacheEntry) path() string {
+	if e.dir == "" || IsAbsPath(e.File) || e.dir == AbsPath(".") {
+		return e.File
+	}
+	return JoinPaths(e.dir, e.File)
+}
+
+// absPath returns the absolute path of the file of the entry
+func (e CacheEntry) absPath() string {
+	if IsAbsPath(e.File) {
+		return e.File
+	}
+	return JoinPaths(cmp.Or(e.dir, AbsPath(".")), e.File)
+}
+
+// CacheRun counts the cache lookups of a Lint run
+type CacheRun struct {
+	HitsClean      int       `json:"hits_clean"`
+	HitsViolations int       `json:"hits_violations"`
+	Misses         int       `json:"misses"`
+	Time           time.Time `json:"time"`
+}
+
+// HitRatio returns the share of lookups served from the cache, 0 when there were none
+func (r CacheRun) HitRatio() float64 {
+	lookups := r.HitsClean + r.HitsViolations + r.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(r.HitsClean+r.HitsViolations) / float64(lookups)
+}
+
+// CacheStats describes the content of the cache
+type CacheStats struct {
+	Entries int       `json:"entries"`            // Cache entries, a file may have several
+	Files   int       `json:"files"`              // Distinct files with an entry
+	Size    int64     `json:"size_bytes"`         // Size of the cache directory
+	LastRun *CacheRun `json:"last_run,omitempty"` // The lookups of the last incremental run, if any
+}
+
+// Entries returns the entries of the cache, ordered by file
+func (c *LintCache) Entries() ([]CacheEntry, error) {
+	var entries []CacheEntry
+	err := afero.Walk(c.fs, JoinPaths(c.root, "manifests"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		content, err := afero.ReadFile(c.fs, path)
+		if err != nil {
+			return err
+		}
+		var manifest granular.Manifest
+		if err := json.Unmarshal(content, &manifest); err != nil || len(manifest.InputDescs) == 0 {
+			// Not a manifest written by the linter
+			return nil
+		}
+
+		entry := CacheEntry{
+			KeyHash:   manifest.KeyHash,
+			File:      strings.TrimPrefix(manifest.InputDescs[0], "file:"),
+			CreatedAt: manifest.CreatedAt,
+			dir:       manifest.OutputMeta["dir"],
+		}
+		if violations, ok := manifest.OutputMeta["violations"]; ok {
+			var lv LintViolations
+			if err := json.Unmarshal([]byte(violations), &lv); err == nil {
+				entry.Violations = len(lv.Violations)
+			}
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, WithFile(NewCacheError("failed to read cache entries", err), c.root)
+	}
+
+	slices.SortFunc(entries, func(a, b CacheEntry) int {
+		return strings.Compare(a.File+"\x00"+a.KeyHash, b.File+"\x00"+b.KeyHash)
+	})
+	return entries, nil
+}
+
+// Stats returns the number of entries and the size of the cache, and the lookups of the last run
+func (c *LintCache) Stats() (CacheStats, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return CacheStats{}, err
+	}
+	stats := CacheStats{Entries: len(entries)}
+	files := make(map[string]bool)
+	for _, entry := range entries {
+		files[entry.File] = true
+	}
+	stats.Files = len(files)
+
+	err = afero.Walk(c.fs, c.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			stats.Size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return CacheStats{}, WithFile(NewCacheError("failed to read cache directory", err), c.root)
+	}
+
+	if content, err := afero.ReadFile(c.fs, JoinPaths(c.root, lastRunFile)); err == nil {
+		var run CacheRun
+		if json.Unmarshal(content, &run) == nil {
+			stats.LastRun = &run
+		}
+	}
+	return stats, nil
+}
+
+// recordRun saves the lookups of a run for Stats
+func (c *LintCache) recordRun(run CacheRun) error {
+	content, err := json.Marshal(run)
+	if err != nil {
+		return NewCacheError("failed to encode cache run", err)
+	}
+	path := JoinPaths(c.root, lastRunFile)
+	if err := afero.WriteFile(c.fs, path, content, 0o644); err != nil {
+		return WithFile(NewCacheError("failed to write cache run", err), path)
+	}
+	return nil
+}
+
+// Clear removes every entry of the cache, and the lookups of the last run
+func (c *LintCache) Clear() error {
+	if err := c.gCache.Clear(); err != nil {
+		return NewCacheError("failed to clear cache", err)
+	}
+	path := JoinPaths(c.root, lastRunFile)
+	if err := c.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+		return WithFile(NewCacheError("failed to remove cache run", err), path)
+	}
+	return nil
+}
+
+// Prune removes the entries of files that no longer exist and returns the number of removed entries.
+// Relative files are looked up from the working directory they were cached from.
+func (c *LintCache) Prune() (int, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if exists, _ := afero.Exists(c.fs, entry.path()); exists {
+			continue
+		}
+		if err := c.gCache.Remove(entry.KeyHash); err != nil {
+			return removed, NewCacheError("failed to remove cache entry of "+entry.File, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// CacheMismatch is a file whose cached violations differ from the ones found by linting it again
+type CacheMismatch struct {
+	File   string          `json:"file"`
+	Cached []LintViolation `json:"cached"`
+	Actual []LintViolation `json:"actual"`
+}
+
+// CacheVerification is the outcome of VerifyCache
+type CacheVerification struct {
+	Checked    int             `json:"checked"` // Files whose cached violations were recomputed
+	Mismatches []CacheMismatch `json:"mismatches"`
+}
+
+// VerifyCache lints again a random sample of the cached files under the given path, all of them
+// when sample is 0 or more than their number, and reports the files whose cached violations differ.
+// Files that changed since they were cached are not checked, as their entries are not used anymore.
+// The linter must have been created with incremental analysis enabled.
+func (g *Goverhaul) VerifyCache(path string, sample int) (CacheVerification, error) {
+	if g.cache == nil {
+		return CacheVerification{}, NewConfigError("incremental analysis is not enabled", nil)
+	}
+	if err := g.startRun(path); err != nil {
+		return CacheVerification{}, err
+	}
+	// Look the entries up like Lint would, without clearing the cache when they do not match
+	fingerprint, err := cacheFingerprint(g.cfg, g.fs, g.modules.workspacePaths())
+	if err != nil {
+		return CacheVerification{}, err
+	}
+	g.cache.useFingerprint(fingerprint)
+	entries, err := g.cache.Entries()
+	if err != nil {
+		return CacheVerification{}, err
+	}
+
+	root := AbsPath(path)
+	var files []string
+	for _, entry := range entries {
+		if !IsSubPath(root, entry.absPath()) {
+			continue
+		}
+		if file := entry.path(); len(files) == 0 || files[len(files)-1] != file {
+			files = append(files, file)
+		}
+	}
+	if sample > 0 && sample < len(files) {
+		rand.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+		files = files[:sample]
+		slices.Sort(files)
+	}
+
+	var verification CacheVerification
+	for _, file := range files {
+		if exists, _ := afero.Exists(g.fs, file); !exists {
+			continue
+		}
+		status, cached := g.cachedViolations(file)
+		if status == CacheMiss {
+			continue
+		}
+
+		verification.Checked++
+		actual := g.lintFile(file).violations
+		expected := slices.Clone(cached.violations)
+		for i := range expected {
+			expected[i].Cached = false
+		}
+		sortViolations(expected)
+		sortViolations(actual)
+		if len(expected) == 0 && len(actual) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			verification.Mismatches = append(verification.Mismatches, CacheMismatch{
+				File:   file,
+				Cached: expected,
+				Actual: actual,
+			})
+		}
+	}
+	return verification, nil
+}
@@ -0,0 +1,207 @@
+package goverhaul
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCachedLinter lints a small tree twice with incremental analysis, so every file is cached
+func newCachedLinter(t *testing.T) (*Goverhaul, afero.Fs) {
+	t.Helper()
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go",
+		[]byte("package api\n\nimport \"unsafe\"\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/clean.go",
+		[]byte("package api\n\nimport \"fmt\"\n"), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules:       []Rule{{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}}},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = linter.Lint("internal")
+		require.ErrorIs(t, err, ErrLint)
+	}
+	return linter, memFs
+}
+
+func TestCacheStats(t *testing.T) {
+	linter, _ := newCachedLinter(t)
+
+	entries, err := linter.cache.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "internal/api/api.go", entries[0].File)
+	assert.Equal(t, 1, entries[0].Violations)
+	assert.Equal(t, "internal/api/clean.go", entries[1].File)
+	assert.Equal(t, 0, entries[1].Violations)
+
+	stats, err := linter.cache.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, 2, stats.Files)
+	assert.Positive(t, stats.Size)
+	require.NotNil(t, stats.LastRun)
+	assert.Equal(t, CacheRun{HitsClean: 1, HitsViolations: 1, Time: stats.LastRun.Time}, *stats.LastRun)
+	assert.InDelta(t, 1.0, stats.LastRun.HitRatio(), 0.001)
+
+	assert.Zero(t, CacheRun{}.HitRatio())
+	assert.InDelta(t, 0.25, CacheRun{HitsClean: 1, Misses: 3}.HitRatio(), 0.001)
+}
+
+func TestCacheClear(t *testing.T) {
+	linter, _ := newCachedLinter(t)
+
+	require.NoError(t, linter.cache.Clear())
+
+	stats, err := linter.cache.Stats()
+	require.NoError(t, err)
+	assert.Zero(t, stats.Entries)
+	assert.Nil(t, stats.LastRun)
+
+	status, _, err := linter.cache.HasEntry("internal/api/api.go")
+	require.NoError(t, err)
+	assert.Equal(t, CacheMiss, status)
+}
+
+func TestCachePrune(t *testing.T) {
+	linter, memFs := newCachedLinter(t)
+
+	removed, err := linter.cache.Prune()
+	require.NoError(t, err)
+	assert.Zero(t, removed, "no file was deleted")
+
+	require.NoError(t, memFs.Remove("internal/api/api.go"))
+	removed, err = linter.cache.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	entries, err := linter.cache.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "internal/api/clean.go", entries[0].File)
+}
+
+func TestCachePruneFromAnotherDirectory(t *testing.T) {
+	dir := t.TempDir()
+	osFs := afero.NewOsFs()
+	require.NoError(t, osFs.MkdirAll(filepath.Join(dir, "internal/api"), 0o755))
+	require.NoError(t, afero.WriteFile(osFs, filepath.Join(dir, "internal/api/api.go"),
+		[]byte("package api\n\nimport \"fmt\"\n"), 0o644))
+	t.Chdir(dir)
+
+	cacheDir := filepath.Join(dir, "cache")
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   cacheDir,
+		Rules:       []Rule{{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}}},
+	}
+	linter, err := NewLinter(cfg, nil, osFs)
+	require.NoError(t, err)
+	_, err = linter.Lint("internal")
+	require.NoError(t, err)
+
+	// The cached paths are relative to the directory of the run, not to the current one
+	t.Chdir(t.TempDir())
+	cache, err := NewCacheWithFs(cacheDir, osFs)
+	require.NoError(t, err)
+	removed, err := cache.Prune()
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	require.NoError(t, osFs.Remove(filepath.Join(dir, "internal/api/api.go")))
+	removed, err = cache.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+}
+
+func TestVerifyCache(t *testing.T) {
+	linter, memFs := newCachedLinter(t)
+
+	verification, err := linter.VerifyCache("internal", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, verification.Checked)
+	assert.Empty(t, verification.Mismatches)
+
+	verification, err = linter.VerifyCache("internal", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, verification.Checked)
+
+	// A stale entry claims a violation the file does not have
+	stale := LintViolation{File: "internal/api/clean.go", Import: "unsafe", Rule: "internal"}
+	require.NoError(t, linter.cache.AddFileWithViolations("internal/api/clean.go", []LintViolation{stale}))
+
+	verification, err = linter.VerifyCache("internal", 0)
+	require.NoError(t, err)
+	require.Len(t, verification.Mismatches, 1)
+	assert.Equal(t, "internal/api/clean.go", verification.Mismatches[0].File)
+	assert.Equal(t, []LintViolation{stale}, verification.Mismatches[0].Cached)
+	assert.Empty(t, verification.Mismatches[0].Actual)
+
+	// Changed files are not checked, their entries are not used anymore
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/clean.go",
+		[]byte("package api\n\nimport \"os\"\n"), 0o644))
+	verification, err = linter.VerifyCache("internal", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, verification.Checked)
+	assert.Empty(t, verification.Mismatches)
+}
+
+func TestVerifyCacheOutsidePath(t *testing.T) {
+	linter, memFs := newCachedLinter(t)
+	require.NoError(t, afero.WriteFile(memFs, "cmd/main.go", []byte("package main\n\nimport \"fmt\"\n"), 0o644))
+	_, err := linter.Lint("cmd")
+	require.NoError(t, err)
+
+	verification, err := linter.VerifyCache("internal", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, verification.Checked, "cmd/main.go is not under the verified path")
+
+	verification, err = linter.VerifyCache("cmd", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, verification.Checked)
+}
+
+func TestVerifyCacheWorkspace(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "services/go.work", []byte("go 1.24\n\nuse (\n\t./api\n\t./billing\n)\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/api/go.mod", []byte("module example.com/api\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/billing/go.mod", []byte("module example.com/billing\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/api/handlers/orders.go",
+		[]byte("package handlers\n\nimport \"example.com/billing/invoices\"\n"), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules:       []Rule{{Path: "services/api/handlers", Allowed: []string{"@module"}}},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+	_, err = linter.Lint("services")
+	require.NoError(t, err)
+
+	// The billing module is part of @module only in the workspace of the linted path
+	verification, err := linter.VerifyCache("services", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, verification.Checked)
+	assert.Empty(t, verification.Mismatches)
+}
+
+func TestVerifyCacheWithoutIncremental(t *testing.T) {
+	linter, err := NewLinter(Config{Modfile: "go.mod"}, nil, afero.NewMemMapFs())
+	require.NoError(t, err)
+
+	_, err = linter.VerifyCache("internal", 0)
+	assert.ErrorIs(t, err, ErrConfig)
+}
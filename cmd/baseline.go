@@ -0,0 +1,63 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+
+	"github.com/gophersatwork/goverhaul"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// defaultBaselineFile is the baseline written when neither --file nor the config name one
+const defaultBaselineFile = ".goverhaul-baseline.json"
+
+var baselineFile string
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the baseline of accepted violations",
+	Long: `A baseline lists existing violations that are not reported, so that only new
+violations fail the build. Set "baseline" in the config to the baseline file to apply it.`,
+}
+
+var baselineWriteCmd = &cobra.Command{
+	Use:   "write",
+	Short: "Write the current violations to the baseline file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger, closeLog, err := newLogger()
+		if err != nil {
+			return err
+		}
+		defer closeLog()
+
+		fs := afero.NewOsFs()
+		cfg, err := loadConfig(cmd, fs, logger)
+		if err != nil {
+			return err
+		}
+
+		target := cmp.Or(baselineFile, cfg.Baseline, defaultBaselineFile)
+		// Snapshot every current violation, including the ones already in the baseline
+		cfg.Baseline = ""
+
+		linter, err := goverhaul.NewLinter(cfg, logger, fs)
+		if err != nil {
+			logger.Error("Failed to initialize the linter", "error", err)
+			return err
+		}
+
+		lv, err := linter.Lint(path)
+		if err != nil && !errors.Is(err, goverhaul.ErrLint) {
+			return err
+		}
+
+		if err := goverhaul.WriteBaseline(fs, target, lv); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d baseline entries to %s\n",
+			len(goverhaul.NewBaseline(lv).Entries), target)
+		return nil
+	},
+}
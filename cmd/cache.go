@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gophersatwork/goverhaul"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var verifySample int
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the incremental analysis cache",
+	Long: `The cache of incremental analysis lives in the directory set by "cache_file" in the config.
+These commands work on it whether or not "incremental" is enabled.`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the number of entries, the size and the hit ratio of the last run",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, cfg, err := openCache(cmd)
+		if err != nil {
+			return err
+		}
+
+		stats, err := cache.Stats()
+		if err != nil {
+			return err
+		}
+		w := cmd.OutOrStdout()
+		fmt.Fprintf(w, "Cache: %s\n", cfg.CacheFile)
+		fmt.Fprintf(w, "Entries: %d (%d files)\n", stats.Entries, stats.Files)
+		fmt.Fprintf(w, "Size: %.1f KiB\n", float64(stats.Size)/1024)
+		if run := stats.LastRun; run != nil {
+			fmt.Fprintf(w, "Last run: %s, %d clean hits, %d hits with violations, %d misses, hit ratio %.1f%%\n",
+				run.Time.Format("2006-01-02 15:04:05"), run.HitsClean, run.HitsViolations, run.Misses, run.HitRatio()*100)
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove every entry of the cache",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, cfg, err := openCache(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := cache.Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Cleared the cache %s\n", cfg.CacheFile)
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the entries of files that no longer exist",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, _, err := openCache(cmd)
+		if err != nil {
+			return err
+		}
+
+		removed, err := cache.Prune()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cache entries of deleted files\n", removed)
+		return nil
+	},
+}
+
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Lint a sample of cached files again and report the ones whose cached violations differ",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger, closeLog, err := newLogger()
+		if err != nil {
+			return err
+		}
+		defer closeLog()
+
+		fs := afero.NewOsFs()
+		cfg, err := loadConfig(cmd, fs, logger)
+		if err != nil {
+			return err
+		}
+		cfg.Incremental = true
+
+		linter, err := goverhaul.NewLinter(cfg, logger, fs)
+		if err != nil {
+			logger.Error("Failed to initialize the linter", "error", err)
+			return err
+		}
+
+		verification, err := linter.VerifyCache(path, verifySample)
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		for _, mismatch := range verification.Mismatches {
+			fmt.Fprintf(w, "%s: %d cached violations, %d actual violations\n",
+				mismatch.File, len(mismatch.Cached), len(mismatch.Actual))
+		}
+		fmt.Fprintf(w, "Verified %d cached files, %d mismatches\n", verification.Checked, len(verification.Mismatches))
+		if len(verification.Mismatches) > 0 {
+			return goverhaul.WithDetails(goverhaul.NewCacheError("cached violations do not match the files", nil),
+				"Run: goverhaul cache clear")
+		}
+		return nil
+	},
+}
+
+// openCache opens the cache of the configuration
+func openCache(cmd *cobra.Command) (*goverhaul.LintCache, goverhaul.Config, error) {
+	logger, closeLog, err := newLogger()
+	if err != nil {
+		return nil, goverhaul.Config{}, err
+	}
+	defer closeLog()
+
+	fs := afero.NewOsFs()
+	cfg, err := loadConfig(cmd, fs, logger)
+	if err != nil {
+		return nil, goverhaul.Config{}, err
+	}
+
+	cache, err := goverhaul.NewCacheWithFs(cfg.CacheFile, fs)
+	if err != nil {
+		return nil, goverhaul.Config{}, goverhaul.WithFile(goverhaul.NewCacheError("failed to open cache", err), cfg.CacheFile)
+	}
+	return cache, cfg, nil
+}
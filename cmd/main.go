@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/fang"
 	"github.com/gophersatwork/goverhaul"
@@ -17,6 +19,18 @@ var (
 	path        string
 	verbose     bool
 	groupByRule bool
+	failOn      string
+	format      string
+	output      string
+	jobs        int
+)
+
+// Exit codes returned by the goverhaul command
+const (
+	exitOK            = 0 // No violations at or above the fail-on severity
+	exitViolations    = 1 // Violations at or above the fail-on severity were found
+	exitConfigError   = 2 // The configuration could not be loaded or is invalid
+	exitInternalError = 3 // Any other failure, e.g. file system or parse errors
 )
 
 func main() {
@@ -24,6 +38,18 @@ func main() {
 	rootCmd.PersistentFlags().StringVar(&path, "path", ".", "path to lint")
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "enable verbose logging")
 	rootCmd.PersistentFlags().BoolVar(&groupByRule, "group-by-rule", false, "group violations by rule instead of by file")
+	rootCmd.PersistentFlags().StringVar(&format, "format", "text", "output format: "+strings.Join(goverhaul.ReporterFormats(), ", "))
+	rootCmd.PersistentFlags().StringVar(&output, "output", "", "write the report to this file instead of stdout")
+	rootCmd.PersistentFlags().StringVar(&failOn, "fail-on", "", "lowest severity that fails the run: error, warning, info or none (overrides fail_on in the config)")
+	rootCmd.PersistentFlags().IntVar(&jobs, "jobs", 0, "number of files analyzed concurrently (default: number of CPUs, overrides jobs in the config)")
+
+	baselineWriteCmd.Flags().StringVar(&baselineFile, "file", "", "baseline file to write (default: baseline from the config, or "+defaultBaselineFile+")")
+	baselineCmd.AddCommand(baselineWriteCmd)
+	rootCmd.AddCommand(baselineCmd)
+
+	cacheVerifyCmd.Flags().IntVar(&verifySample, "sample", 20, "number of cached files to lint again, 0 for all of them")
+	cacheCmd.AddCommand(cacheStatsCmd, cacheClearCmd, cachePruneCmd, cacheVerifyCmd)
+	rootCmd.AddCommand(cacheCmd)
 
 	// Execute the command and handle errors
 	if err := fang.Execute(context.Background(), rootCmd); err != nil {
@@ -55,7 +81,21 @@ func main() {
 			}
 		}
 		logger.Error("Command failed", "error", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode maps an error returned by the command to the process exit code
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, goverhaul.ErrLint):
+		return exitViolations
+	case errors.Is(err, goverhaul.ErrConfig):
+		return exitConfigError
+	default:
+		return exitInternalError
 	}
 }
 
@@ -64,41 +104,20 @@ var rootCmd = &cobra.Command{
 	Short: "A linter for Go architecture",
 	Long:  `Goverhaul is a CLI tool to enforce architectural rules in Go projects.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Initialize logger
-		logLevel := slog.LevelInfo
-		if verbose {
-			logLevel = slog.LevelDebug
+		logger, closeLog, err := newLogger()
+		if err != nil {
+			return err
 		}
+		defer closeLog()
 
-		// Set up logger
-		var logger *slog.Logger
-		if verbose {
-			// When verbose is true, log to stdout for better visibility
-			logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-				Level: logLevel,
-			}))
-		} else {
-			// Otherwise, log to file
-			logFile, err := setupLogFile()
-			if err != nil {
-				// Fall back to stdout if we can't create the log file
-				logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-					Level: logLevel,
-				}))
-				logger.Error("Failed to set up log file, falling back to stdout", "error", err)
-				return err
-			}
-			defer logFile.Close()
-
-			logger = slog.New(slog.NewTextHandler(logFile, &slog.HandlerOptions{
-				Level: logLevel,
-			}))
+		fs := afero.NewOsFs() // real fs binding
+		cfg, err := loadConfig(cmd, fs, logger)
+		if err != nil {
+			return err
 		}
 
-		fs := afero.NewOsFs() // real fs binding
-		cfg, err := goverhaul.LoadConfig(fs, path, cfgFile)
+		reporter, err := newReporter(cfg)
 		if err != nil {
-			logger.Error("Failed to load configuration", "error", err)
 			return err
 		}
 
@@ -109,20 +128,102 @@ var rootCmd = &cobra.Command{
 		}
 
 		lv, err := linter.Lint(path)
-		if err != nil {
+		if lv == nil {
 			return err
 		}
 
-		if groupByRule {
-			fmt.Println(lv.PrintByRule())
-		} else {
-			fmt.Println(lv.PrintByFile())
+		if writeErr := writeReport(reporter, lv, linter.Summary()); writeErr != nil {
+			return writeErr
 		}
 
-		return nil
+		// err is nil, wraps ErrLint, which maps to the violations exit code,
+		// or wraps ErrParse when files could not be parsed, which maps to the internal error exit code
+		return err
 	},
 }
 
+// newLogger creates the logger of a run: verbose runs log to the console, other runs to the log file.
+// The returned function closes the log file.
+func newLogger() (*slog.Logger, func(), error) {
+	logLevel := slog.LevelInfo
+	if verbose {
+		logLevel = slog.LevelDebug
+		// When verbose is true, log to stdout for better visibility,
+		// unless stdout is reserved for a machine-readable report
+		logOutput := os.Stdout
+		if format != "text" {
+			logOutput = os.Stderr
+		}
+		return slog.New(slog.NewTextHandler(logOutput, &slog.HandlerOptions{
+			Level: logLevel,
+		})), func() {}, nil
+	}
+
+	// Otherwise, log to file
+	logFile, err := setupLogFile()
+	if err != nil {
+		// Fall back to stdout if we can't create the log file
+		logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+			Level: logLevel,
+		}))
+		logger.Error("Failed to set up log file, falling back to stdout", "error", err)
+		return nil, nil, err
+	}
+
+	logger := slog.New(slog.NewTextHandler(logFile, &slog.HandlerOptions{
+		Level: logLevel,
+	}))
+	return logger, func() { logFile.Close() }, nil
+}
+
+// loadConfig loads the configuration and applies the command line overrides
+func loadConfig(cmd *cobra.Command, fs afero.Fs, logger *slog.Logger) (goverhaul.Config, error) {
+	cfg, err := goverhaul.LoadConfig(fs, path, cfgFile)
+	if err != nil {
+		logger.Error("Failed to load configuration", "error", err)
+		return goverhaul.Config{}, err
+	}
+
+	if cmd.Flags().Changed("fail-on") {
+		cfg.FailOn, err = goverhaul.ParseSeverity(failOn)
+		if err != nil {
+			return goverhaul.Config{}, goverhaul.WithDetails(err, "--fail-on must be one of: error, warning, info, none")
+		}
+	}
+
+	if cmd.Flags().Changed("jobs") {
+		cfg.Jobs = jobs
+	}
+
+	return cfg, nil
+}
+
+// newReporter creates the reporter selected with --format
+func newReporter(cfg goverhaul.Config) (goverhaul.Reporter, error) {
+	if groupByRule && format == "text" {
+		return goverhaul.TextReporter{GroupByRule: true}, nil
+	}
+	return goverhaul.NewReporter(format, cfg)
+}
+
+// writeReport writes the lint report to stdout or to the --output file
+func writeReport(reporter goverhaul.Reporter, lv *goverhaul.LintViolations, summary goverhaul.RunSummary) error {
+	w := io.Writer(os.Stdout)
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return goverhaul.WithFile(goverhaul.NewFSError("failed to create report file", err), output)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := reporter.Report(w, lv, summary); err != nil {
+		return goverhaul.NewError("failed to write "+format+" report", err)
+	}
+	return nil
+}
+
 // setupLogFile creates the .goverhaul directory if it doesn't exist and returns a file handle for the log file
 func setupLogFile() (*os.File, error) {
 	home, err := os.UserHomeDir()
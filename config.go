@@ -1,6 +1,7 @@
 package goverhaul
 
 import (
+	"cmp"
 	"errors"
 	"strings"
 
@@ -13,17 +14,77 @@ type Config struct {
 	Modfile     string `yaml:"modfile" mapstructure:"modfile"`
 	Incremental bool   `yaml:"incremental" mapstructure:"incremental"`
 	CacheFile   string `yaml:"cache_file" mapstructure:"cache_file"`
+	// FailOn is the lowest severity that makes Lint return ErrLint (default: error)
+	FailOn Severity `yaml:"fail_on" mapstructure:"fail_on"`
+	// Layers lists the architecture layers from top to bottom; a layer may only import layers below it
+	Layers   []Layer      `yaml:"layers" mapstructure:"layers"`
+	Layering LayeringMode `yaml:"layering" mapstructure:"layering"`
+	// NoCycles lists component patterns, such as "services/*", between which import cycles are reported
+	NoCycles []string `yaml:"no_cycles" mapstructure:"no_cycles"`
+	// Baseline is the path of a baseline file whose violations are not reported
+	Baseline string `yaml:"baseline" mapstructure:"baseline"`
+	// Jobs is the number of files analyzed concurrently (default: the number of CPUs)
+	Jobs int `yaml:"jobs" mapstructure:"jobs"`
+	// Exclude lists path patterns of the files and directories that are not linted
+	Exclude []string `yaml:"exclude" mapstructure:"exclude"`
+	// Gitignore makes the walk skip the files and directories ignored by .gitignore files
+	Gitignore bool `yaml:"gitignore" mapstructure:"gitignore"`
+	// Tests is how rules treat _test.go files (default: like the other files)
+	Tests *TestsPolicy `yaml:"tests" mapstructure:"tests"`
+	// BuildContexts lists the targets files are checked for (default: every file, regardless of its constraints)
+	BuildContexts []BuildContext `yaml:"build_contexts" mapstructure:"build_contexts"`
+	// Generated is how generated files are linted (default: they are skipped)
+	Generated GeneratedPolicy `yaml:"generated" mapstructure:"generated"`
+	// Analysis is how imports are resolved: as written, or with the go command (default: syntax)
+	Analysis AnalysisMode `yaml:"analysis" mapstructure:"analysis"`
+}
+
+// EffectiveRules returns the configured rules followed by the rules derived from the layers
+func (c Config) EffectiveRules() []Rule {
+	rules := make([]Rule, 0, len(c.Rules))
+	rules = append(rules, c.Rules...)
+	return append(rules, layerRules(c.Layers, c.Layering)...)
 }
 
 type Rule struct {
-	Path       string          `yaml:"path" mapstructure:"path"`
-	Allowed    []string        `yaml:"allowed" mapstructure:"allowed"`
-	Prohibited []ProhibitedPkg `yaml:"prohibited" mapstructure:"prohibited"`
+	// ID names the rule in reports instead of its path (optional)
+	ID          string   `yaml:"id" mapstructure:"id"`
+	Path        string   `yaml:"path" mapstructure:"path"`
+	Severity    Severity `yaml:"severity" mapstructure:"severity"` // Severity of the rule's violations (default: error)
+	Description string   `yaml:"description" mapstructure:"description"`
+	Allowed     []string `yaml:"allowed" mapstructure:"allowed"`
+	// AllowedMode is how plain allowed entries are matched (default: exact)
+	AllowedMode MatchMode `yaml:"allowed_mode" mapstructure:"allowed_mode"`
+	// AllowedTransitive also checks the packages reached through the packages of the module against Allowed
+	AllowedTransitive bool            `yaml:"allowed_transitive" mapstructure:"allowed_transitive"`
+	Prohibited        []ProhibitedPkg `yaml:"prohibited" mapstructure:"prohibited"`
+	// Tests replaces the global tests policy for the rule
+	Tests *TestsPolicy `yaml:"tests" mapstructure:"tests"`
+	// Contexts restricts the rule to the named build contexts (default: all of them)
+	Contexts []string `yaml:"contexts" mapstructure:"contexts"`
 }
 
+// MatchMode controls how an import list entry is matched against imports
+type MatchMode string
+
+const (
+	// MatchExact matches the import path only: "encoding" does not match "encoding/json"
+	MatchExact MatchMode = "exact"
+	// MatchSubtree matches the import path and every package below it: "internal/domain" matches "internal/domain/user"
+	MatchSubtree MatchMode = "subtree"
+	// MatchPattern interprets the entry as a pattern with "*", "**" and "..." wildcards
+	MatchPattern MatchMode = "pattern"
+)
+
 type ProhibitedPkg struct {
-	Name  string `yaml:"name" mapstructure:"name"`
-	Cause string `yaml:"cause" mapstructure:"cause"`
+	// ID is the RuleID of the entry's violations (optional, derived from the rule otherwise)
+	ID          string   `yaml:"id" mapstructure:"id"`
+	Name        string   `yaml:"name" mapstructure:"name"`
+	Cause       string   `yaml:"cause" mapstructure:"cause"`
+	Severity    Severity `yaml:"severity" mapstructure:"severity"` // Overrides the severity of the rule
+	Description string   `yaml:"description" mapstructure:"description"`
+	// Transitive also reports the package when it is reached through other packages of the module
+	Transitive bool `yaml:"transitive" mapstructure:"transitive"`
 }
 
 func LoadConfig(fs afero.Fs, path string, cfgFile string) (Config, error) {
@@ -67,6 +128,8 @@ func LoadConfig(fs afero.Fs, path string, cfgFile string) (Config, error) {
 	viper.SetDefault("rules", []Rule{})
 	viper.SetDefault("modfile", "go.mod")
 	viper.SetDefault("cache_file", "cache.json")
+	viper.SetDefault("fail_on", string(SeverityError))
+	viper.SetDefault("layering", string(LayeringRelaxed))
 
 	var config Config
 	err := viper.Unmarshal(&config)
@@ -74,5 +137,96 @@ func LoadConfig(fs afero.Fs, path string, cfgFile string) (Config, error) {
 		return Config{}, NewConfigError("failed unmarshaling config file", err)
 	}
 
+	config.FailOn, err = ParseSeverity(string(config.FailOn))
+	if err != nil {
+		return Config{}, WithDetails(err, "fail_on must be one of: error, warning, info, none")
+	}
+
+	if err := normalizeRuleSeverities(config.Rules); err != nil {
+		return Config{}, err
+	}
+	if err := normalizeRuleSeverities(config.Generated.Rules); err != nil {
+		return Config{}, WithDetails(err, "Generated rules")
+	}
+	if err := validateGeneratedPolicy(config.Generated); err != nil {
+		return Config{}, err
+	}
+
+	if err := validateTestsPolicy(config.Tests); err != nil {
+		return Config{}, err
+	}
+	if err := validateAnalysisMode(config.Analysis, nil); err != nil {
+		return Config{}, err
+	}
+	if err := validateBuildContexts(config.BuildContexts, append(config.Rules, config.Generated.Rules...)); err != nil {
+		return Config{}, err
+	}
+	for _, rule := range config.Rules {
+		if err := validateTestsPolicy(rule.Tests); err != nil {
+			return Config{}, WithDetails(err, "Rule: "+ruleName(rule))
+		}
+	}
+
+	if err := validateLayers(config.Layers, config.Layering); err != nil {
+		return Config{}, err
+	}
+
 	return config, nil
 }
+
+// normalizeRuleSeverities parses the severities of the rules and their prohibited entries in place
+func normalizeRuleSeverities(rules []Rule) error {
+	for i := range rules {
+		rule := &rules[i]
+		var err error
+		if rule.Severity, err = parseRuleSeverity(rule.Severity); err != nil {
+			return WithDetails(err, "Rule: "+ruleName(*rule))
+		}
+		for j := range rule.Prohibited {
+			prohibited := &rule.Prohibited[j]
+			if prohibited.Severity, err = parseRuleSeverity(prohibited.Severity); err != nil {
+				return WithDetails(err, "Rule: "+ruleName(*rule)+", prohibited: "+prohibited.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// parseRuleSeverity parses the severity of a rule. Unlike fail_on, an empty severity stays
+// empty so that prohibited entries inherit the severity of their rule.
+func parseRuleSeverity(severity Severity) (Severity, error) {
+	if severity == "" {
+		return "", nil
+	}
+	parsed, err := ParseSeverity(string(severity))
+	if err != nil || parsed == SeverityNone {
+		return "", WithDetails(NewConfigError("invalid rule severity "+string(severity), err),
+			"severity must be one of: error, warning, info")
+	}
+	return parsed, nil
+}
+
+// ruleName returns the name violations of a rule are reported under: its id, or its path
+func ruleName(rule Rule) string {
+	return cmp.Or(rule.ID, rule.Path)
+}
+
+// allowedRuleID returns the identifier of the allow-list check of a rule
+func allowedRuleID(rule Rule) string {
+	return ruleName(rule) + ":allowed"
+}
+
+// prohibitedRuleID returns the identifier of a prohibited entry of a rule
+func prohibitedRuleID(rule Rule, prohibited ProhibitedPkg) string {
+	return cmp.Or(prohibited.ID, ruleName(rule)+":prohibited:"+prohibited.Name)
+}
+
+// allowedSeverity returns the severity of the allow-list violations of a rule
+func allowedSeverity(rule Rule) Severity {
+	return cmp.Or(rule.Severity, SeverityError)
+}
+
+// prohibitedSeverity returns the severity of the violations of a prohibited entry
+func prohibitedSeverity(rule Rule, prohibited ProhibitedPkg) Severity {
+	return cmp.Or(prohibited.Severity, rule.Severity, SeverityError)
+}
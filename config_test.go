@@ -101,6 +101,87 @@ func TestInvalidYamlConfig(t *testing.T) {
 	require.Equal(t, "failed loading config file: While parsing config: yaml: line 2: found character that cannot start any token", err.Error())
 }
 
+func TestInvalidFailOnConfig(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+
+	afero.WriteFile(memFs, "config", []byte("fail_on: fatal\n"), 0o644)
+	_, err := LoadConfig(memFs, ".", "config")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrConfig)
+}
+
+func TestRuleSeverityConfig(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+
+	afero.WriteFile(memFs, "config", []byte(`
+rules:
+  - id: "api-boundaries"
+    path: "internal/api"
+    severity: Warning
+    description: "The API talks to services only"
+    prohibited:
+      - name: "internal/db"
+        severity: info
+      - name: "internal/cache"
+`), 0o644)
+	cfg, err := LoadConfig(memFs, ".", "config")
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Rules, 1)
+	assert.Equal(t, "api-boundaries", cfg.Rules[0].ID)
+	assert.Equal(t, SeverityWarning, cfg.Rules[0].Severity)
+	assert.Equal(t, "The API talks to services only", cfg.Rules[0].Description)
+	assert.Equal(t, SeverityInfo, cfg.Rules[0].Prohibited[0].Severity)
+	assert.Equal(t, Severity(""), cfg.Rules[0].Prohibited[1].Severity)
+}
+
+func TestInvalidRuleSeverityConfig(t *testing.T) {
+	tests := map[string]string{
+		"unknown rule severity":       "rules:\n  - path: cmd\n    severity: fatal\n",
+		"none is not a rule severity": "rules:\n  - path: cmd\n    severity: none\n",
+		"unknown prohibited severity": "rules:\n  - path: cmd\n    prohibited:\n      - name: unsafe\n        severity: fatal\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			memFs := afero.NewMemMapFs()
+
+			afero.WriteFile(memFs, "config", []byte(content), 0o644)
+			_, err := LoadConfig(memFs, ".", "config")
+			assert.ErrorIs(t, err, ErrConfig)
+		})
+	}
+}
+
+func TestTestsPolicyConfig(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+
+	afero.WriteFile(memFs, "config", []byte(`
+tests:
+  mode: exclude
+  packages: external
+rules:
+  - path: "internal/domain"
+    allowed:
+      - "@stdlib"
+    tests:
+      mode: separate
+      allowed:
+        - "github.com/stretchr/testify/..."
+`), 0o644)
+	cfg, err := LoadConfig(memFs, ".", "config")
+	require.NoError(t, err)
+
+	assert.Equal(t, &TestsPolicy{Mode: TestsExclude, Packages: TestPackagesExternal}, cfg.Tests)
+	require.NotNil(t, cfg.Rules[0].Tests)
+	assert.Equal(t, TestsSeparate, cfg.Rules[0].Tests.Mode)
+	assert.Equal(t, []string{"github.com/stretchr/testify/..."}, cfg.Rules[0].Tests.Allowed)
+
+	afero.WriteFile(memFs, "invalid", []byte("tests:\n  mode: skip\n"), 0o644)
+	_, err = LoadConfig(memFs, ".", "invalid")
+	assert.ErrorIs(t, err, ErrConfig)
+}
+
 func defaultConfigTestFile(t *testing.T) []byte {
 	t.Helper()
 
@@ -110,6 +191,7 @@ rules:
     allowed:
       - "fmt"
       - "errors"
+    allowed_mode: "subtree"
     prohibited:
       - name: "unsafe"
         cause: "unsafe code is not allowed in internal packages"
@@ -131,6 +213,7 @@ func assertDefaultConfigTestFile(t *testing.T, config Config) {
 
 	assert.Equal(t, "internal", config.Rules[0].Path)
 	assert.EqualValues(t, []string{"fmt", "errors"}, config.Rules[0].Allowed)
+	assert.Equal(t, MatchSubtree, config.Rules[0].AllowedMode)
 	assert.Equal(t, []ProhibitedPkg{{
 		Name:  "unsafe",
 		Cause: "unsafe code is not allowed in internal packages",
@@ -150,6 +233,7 @@ func assertDefaultValues(t *testing.T, config Config) {
 	assert.Equal(t, "go.mod", config.Modfile)
 	assert.False(t, config.Incremental)
 	assert.Equal(t, "cache.json", config.CacheFile)
+	assert.Equal(t, SeverityError, config.FailOn)
 
 	assert.Empty(t, config.Rules)
 }
@@ -0,0 +1,237 @@
+package goverhaul
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// noCyclesRule is the rule name of the violations reported for component cycles
+const noCyclesRule = "no_cycles"
+
+// componentGraph records the imports between the components declared in no_cycles.
+// A package belongs to the component formed by the shortest leading part of its
+// path matched by the first matching pattern, so "services/*" makes every
+// directory below "services" its own component.
+type componentGraph struct {
+	patterns []pathPattern
+	edges    map[string]map[string]componentEdge // Importing component -> imported component -> first import
+}
+
+// componentEdge is the import establishing a dependency between two components
+type componentEdge struct {
+	file string
+	imp  importRef
+}
+
+// before reports whether the edge comes before the other one in the source tree
+func (e componentEdge) before(other componentEdge) bool {
+	return cmp.Or(
+		cmp.Compare(e.file, other.file),
+		cmp.Compare(e.imp.Pos.Line, other.imp.Pos.Line),
+		cmp.Compare(e.imp.Pos.Column, other.imp.Pos.Column),
+	) < 0
+}
+
+// newComponentGraph compiles the component patterns of the no_cycles rule
+func newComponentGraph(patterns []string) (*componentGraph, error) {
+	graph := &componentGraph{edges: make(map[string]map[string]componentEdge)}
+	for _, raw := range patterns {
+		p, err := compilePattern(raw)
+		if err != nil {
+			return nil, WithDetails(err, "no_cycles component: "+raw)
+		}
+		graph.patterns = append(graph.patterns, p)
+	}
+	return graph, nil
+}
+
+// component returns the component a package directory belongs to
+func (g *componentGraph) component(dir string) (string, bool) {
+	for _, p := range g.patterns {
+		if prefix, ok := p.matchedPrefix(dir); ok {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// inComponentGraph reports whether the imports of a file are component dependencies.
+// Test files are left out: they are not part of the built components, and external
+// test packages exist precisely to import packages that depend on their own.
+func inComponentGraph(file string) bool {
+	return !strings.HasSuffix(file, "_test.go")
+}
+
+// addImports records the imports of a file of the given module. Imports are resolved to
+// directories through the module, or the workspace modules, they belong to. Imports outside
+// of these modules or within the importing component are not component dependencies.
+func (g *componentGraph) addImports(file string, module goModule, workspace []goModule, imports []importRef) {
+	from, ok := g.component(DirPath(file))
+	if !ok {
+		return
+	}
+
+	modules := append([]goModule{module}, workspace...)
+	for _, imp := range imports {
+		var dir string
+		for _, m := range modules {
+			if dir, ok = m.importDir(imp.Path); ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		to, ok := g.component(dir)
+		if !ok || to == from {
+			continue
+		}
+
+		if g.edges[from] == nil {
+			g.edges[from] = make(map[string]componentEdge)
+		}
+		edge := componentEdge{file: NormalizePath(file), imp: imp}
+		if existing, exists := g.edges[from][to]; !exists || edge.before(existing) {
+			g.edges[from][to] = edge
+		}
+	}
+}
+
+// cycles returns one cycle per group of mutually dependent components.
+// Each cycle starts and ends with the lexically smallest component of its group
+// and is the shortest cycle through it.
+func (g *componentGraph) cycles() [][]string {
+	var cycles [][]string
+	for _, scc := range g.stronglyConnected() {
+		if len(scc) < 2 {
+			continue
+		}
+		cycles = append(cycles, g.shortestCycle(scc))
+	}
+	return cycles
+}
+
+// stronglyConnected returns the strongly connected components of the graph (Tarjan's algorithm)
+func (g *componentGraph) stronglyConnected() [][]string {
+	nodes := g.nodes()
+	index := make(map[string]int, len(nodes))
+	lowlink := make(map[string]int, len(nodes))
+	onStack := make(map[string]bool, len(nodes))
+	var stack []string
+	var sccs [][]string
+
+	var visit func(node string)
+	visit = func(node string) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range g.successors(node) {
+			if _, visited := index[next]; !visited {
+				visit(next)
+				lowlink[node] = min(lowlink[node], lowlink[next])
+			} else if onStack[next] {
+				lowlink[node] = min(lowlink[node], index[next])
+			}
+		}
+
+		if lowlink[node] != index[node] {
+			return
+		}
+		var scc []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			scc = append(scc, top)
+			if top == node {
+				break
+			}
+		}
+		slices.Sort(scc)
+		sccs = append(sccs, scc)
+	}
+
+	for _, node := range nodes {
+		if _, visited := index[node]; !visited {
+			visit(node)
+		}
+	}
+
+	slices.SortFunc(sccs, func(a, b []string) int { return cmp.Compare(a[0], b[0]) })
+	return sccs
+}
+
+// shortestCycle returns the shortest cycle through the first component of a strongly connected group
+func (g *componentGraph) shortestCycle(scc []string) []string {
+	start := scc[0]
+	inGroup := make(map[string]bool, len(scc))
+	for _, node := range scc {
+		inGroup[node] = true
+	}
+
+	// Breadth-first search from start until an edge leads back to it
+	previous := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range g.successors(node) {
+			if next == start {
+				cycle := []string{start}
+				for n := node; n != start; n = previous[n] {
+					cycle = append(cycle, n)
+				}
+				slices.Reverse(cycle[1:])
+				return append(cycle, start)
+			}
+			if _, seen := previous[next]; seen || !inGroup[next] {
+				continue
+			}
+			previous[next] = node
+			queue = append(queue, next)
+		}
+	}
+	return scc
+}
+
+// nodes returns the sorted components having dependencies
+func (g *componentGraph) nodes() []string {
+	nodes := make([]string, 0, len(g.edges))
+	for node := range g.edges {
+		nodes = append(nodes, node)
+	}
+	slices.Sort(nodes)
+	return nodes
+}
+
+// successors returns the sorted components imported by a component
+func (g *componentGraph) successors(node string) []string {
+	successors := make([]string, 0, len(g.edges[node]))
+	for next := range g.edges[node] {
+		successors = append(successors, next)
+	}
+	slices.Sort(successors)
+	return successors
+}
+
+// violations reports every cycle at the import leading from its first component to the next one
+func (g *componentGraph) violations() []LintViolation {
+	var violations []LintViolation
+	for _, cycle := range g.cycles() {
+		edge := g.edges[cycle[0]][cycle[1]]
+		members := slices.Clone(cycle[:len(cycle)-1])
+		slices.Sort(members)
+
+		v := createViolation(edge.file, edge.imp.Path, noCyclesRule, noCyclesRule,
+			fmt.Sprintf("Components %s form an import cycle", strings.Join(members, ", ")),
+			"Import cycle: "+strings.Join(cycle, " -> "))
+		v.Line, v.Column = edge.imp.Pos.Line, edge.imp.Pos.Column
+		v.EndLine, v.EndColumn = edge.imp.End.Line, edge.imp.End.Column
+		violations = append(violations, *v)
+	}
+	return violations
+}
@@ -0,0 +1,121 @@
+package goverhaul
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentGraphCycles(t *testing.T) {
+	tests := map[string]struct {
+		edges    [][2]string
+		expected [][]string
+	}{
+		"no cycle": {
+			edges:    [][2]string{{"a", "b"}, {"b", "c"}},
+			expected: nil,
+		},
+		"two components": {
+			edges:    [][2]string{{"b", "a"}, {"a", "b"}},
+			expected: [][]string{{"a", "b", "a"}},
+		},
+		"shortest cycle through the first component": {
+			edges:    [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"b", "a"}},
+			expected: [][]string{{"a", "b", "a"}},
+		},
+		"separate cycles": {
+			edges:    [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"x", "y"}, {"y", "x"}, {"c", "x"}},
+			expected: [][]string{{"a", "b", "c", "a"}, {"x", "y", "x"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			graph, err := newComponentGraph(nil)
+			require.NoError(t, err)
+			for _, edge := range test.edges {
+				if graph.edges[edge[0]] == nil {
+					graph.edges[edge[0]] = make(map[string]componentEdge)
+				}
+				graph.edges[edge[0]][edge[1]] = componentEdge{}
+			}
+
+			assert.Equal(t, test.expected, graph.cycles())
+		})
+	}
+}
+
+func TestComponentGraphComponent(t *testing.T) {
+	graph, err := newComponentGraph([]string{"services/*", "pkg/common"})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		dir       string
+		component string
+		ok        bool
+	}{
+		"wildcard component":       {dir: "services/user", component: "services/user", ok: true},
+		"package below a wildcard": {dir: "services/user/http", component: "services/user", ok: true},
+		"literal component":        {dir: "pkg/common/strings", component: "pkg/common", ok: true},
+		"outside of components":    {dir: "cmd/server", ok: false},
+		"parent of a component":    {dir: "services", ok: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			component, ok := graph.component(test.dir)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.component, component)
+		})
+	}
+}
+
+func TestNewComponentGraphInvalidPattern(t *testing.T) {
+	_, err := newComponentGraph([]string{"services/[a-"})
+	assert.ErrorIs(t, err, ErrConfig)
+}
+
+func TestLintNoCycles(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"go.mod":                        "module example.com\n\ngo 1.20\n",
+		"services/user/user.go":         "package user\n\nimport (\n\t\"fmt\"\n\t\"example.com/pkg/common\"\n)\n",
+		"services/user/http/handler.go": "package http\n\nimport _ \"example.com/services/user\"\n",
+		"services/order/order.go":       "package order\n\nimport _ \"example.com/pkg/common/ids\"\n",
+		"pkg/common/common.go":          "package common\n",
+		// External test packages may import components depending on the package under test
+		"pkg/common/common_test.go":    "package common_test\n\nimport _ \"example.com/services/order\"\n",
+		"services/order/order_test.go": "package order\n\nimport _ \"example.com/services/user\"\n",
+		"services/user/user_test.go":   "package user_test\n\nimport _ \"example.com/services/order\"\n",
+		"pkg/common/ids/ids.go":        "package ids\n\nimport _ \"example.com/services/user/http\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+
+	cfg := Config{
+		Modfile:  "go.mod",
+		NoCycles: []string{"services/*", "pkg/common"},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint(".")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 1)
+
+	v := violations.Violations[0]
+	assert.Equal(t, "pkg/common/ids/ids.go", v.File)
+	assert.Equal(t, "example.com/services/user/http", v.Import)
+	assert.Equal(t, 3, v.Line)
+	assert.Equal(t, noCyclesRule, v.Rule)
+	assert.Equal(t, "Components pkg/common, services/user form an import cycle", v.Cause)
+	assert.Equal(t, "Import cycle: pkg/common -> services/user -> pkg/common", v.Details)
+
+	// A second run must not report the cycle twice
+	violations, err = linter.Lint(".")
+	require.ErrorIs(t, err, ErrLint)
+	assert.Len(t, violations.Violations, 1)
+}
@@ -1,15 +1,29 @@
 package goverhaul
 
 import (
+	"errors"
 	"fmt"
 )
 
+// Error categories used to classify an AppError with errors.Is
+var (
+	// ErrConfig marks errors caused by a missing or invalid configuration
+	ErrConfig = errors.New("configuration error")
+	// ErrFS marks errors caused by file system access
+	ErrFS = errors.New("file system error")
+	// ErrParse marks errors caused by unparsable input files
+	ErrParse = errors.New("parse error")
+	// ErrCache marks errors caused by the incremental analysis cache
+	ErrCache = errors.New("cache error")
+)
+
 // AppError represents an application error with additional context
 type AppError struct {
 	Message string
 	File    string
 	Details string
 	Err     error
+	Kind    error // The error category (ErrConfig, ErrFS, ...), if any
 }
 
 // Error implements the error interface
@@ -41,6 +55,11 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether the error belongs to the target category
+func (e *AppError) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
 // GetErrorInfo extracts error information from an error
 func GetErrorInfo(err error) (*AppError, bool) {
 	if err == nil {
@@ -103,19 +122,28 @@ func NewError(message string, err error) error {
 	}
 }
 
+// newKindError creates a new application error of the given category
+func newKindError(kind error, message string, err error) error {
+	return &AppError{
+		Message: message,
+		Err:     err,
+		Kind:    kind,
+	}
+}
+
 // NewConfigError creates a new configuration error
 func NewConfigError(message string, err error) error {
-	return NewError(message, err)
+	return newKindError(ErrConfig, message, err)
 }
 
 // NewFSError creates a new file system error
 func NewFSError(message string, err error) error {
-	return NewError(message, err)
+	return newKindError(ErrFS, message, err)
 }
 
 // NewParseError creates a new parsing error
 func NewParseError(message string, err error) error {
-	return NewError(message, err)
+	return newKindError(ErrParse, message, err)
 }
 
 // NewLintError creates a new linting error
@@ -125,5 +153,5 @@ func NewLintError(message string, err error) error {
 
 // NewCacheError creates a new cache error
 func NewCacheError(message string, err error) error {
-	return NewError(message, err)
+	return newKindError(ErrCache, message, err)
 }
@@ -0,0 +1,222 @@
+package goverhaul
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// skippedDirs are the directories the go tool ignores when matching "./..."
+var skippedDirs = map[string]bool{
+	"vendor":       true,
+	"testdata":     true,
+	"node_modules": true,
+}
+
+// walkFilter decides which files and directories the walk skips: the files and directories ignored
+// by the go tool, the ones matching Config.Exclude and, when enabled, the ones ignored by .gitignore.
+// It is used by the walker goroutine only.
+type walkFilter struct {
+	fs        afero.Fs
+	root      string        // The linted path, which is never skipped
+	exclude   []pathPattern // Compiled Config.Exclude patterns
+	gitignore bool          // Whether .gitignore files are honored
+	ignores   []ignoreRule  // The .gitignore rules of the parents of the root and of the directories walked so far
+}
+
+// compileExcludes compiles the exclude patterns of the configuration
+func compileExcludes(raw []string) ([]pathPattern, error) {
+	patterns := make([]pathPattern, 0, len(raw))
+	for _, entry := range raw {
+		p, err := compilePattern(entry)
+		if err != nil {
+			return nil, WithDetails(err, "Exclude pattern: "+entry)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// newWalkFilter creates the filter of a walk starting at root
+func newWalkFilter(fs afero.Fs, root string, exclude []pathPattern, gitignore bool) *walkFilter {
+	return &walkFilter{
+		fs:        fs,
+		root:      NormalizePath(root),
+		exclude:   exclude,
+		gitignore: gitignore,
+	}
+}
+
+// skip reports whether a walked path is skipped
+func (f *walkFilter) skip(name string, isDir bool) (bool, error) {
+	name = NormalizePath(name)
+	if name == f.root {
+		if err := f.loadParentGitignores(isDir); err != nil {
+			return false, err
+		}
+		return false, f.loadGitignore(name, isDir)
+	}
+
+	// Like the go tool, skip the files and directories starting with "." or "_"
+	base := path.Base(name)
+	if isDir && skippedDirs[base] || strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") {
+		return true, nil
+	}
+	for _, p := range f.exclude {
+		if p.match(name) {
+			return true, nil
+		}
+	}
+	if f.ignored(name, isDir) {
+		return true, nil
+	}
+	return false, f.loadGitignore(name, isDir)
+}
+
+// loadGitignore reads the .gitignore file of a walked directory, if any
+func (f *walkFilter) loadGitignore(dir string, isDir bool) error {
+	if !f.gitignore || !isDir {
+		return nil
+	}
+
+	content, err := readGitignore(f.fs, dir)
+	if err != nil {
+		return err
+	}
+	f.ignores = append(f.ignores, parseGitignore(dir, content)...)
+	return nil
+}
+
+// loadParentGitignores reads the .gitignore files of the parents of the root directory, up to
+// the root of its git repository, as git applies them to the files of the root too.
+// Nothing is read when the root is not in a git repository.
+func (f *walkFilter) loadParentGitignores(isDir bool) error {
+	if !f.gitignore || !isDir {
+		return nil
+	}
+
+	root := AbsPath(f.root)
+	var parents []string
+	for dir := root; ; {
+		if exists, _ := afero.Exists(f.fs, JoinPaths(dir, ".git")); exists {
+			break
+		}
+		parent := DirPath(dir)
+		if parent == dir {
+			return nil
+		}
+		parents = append(parents, parent)
+		dir = parent
+	}
+
+	// The outermost rules come first, so that the rules of nested directories win
+	for _, dir := range slices.Backward(parents) {
+		content, err := readGitignore(f.fs, dir)
+		if err != nil {
+			return err
+		}
+		prefix, err := filepath.Rel(dir, root)
+		if err != nil {
+			continue
+		}
+		// The rules match the walked paths, which are relative to the root, once prefixed
+		// with the path of the root relative to the .gitignore directory
+		for _, rule := range parseGitignore(f.root, content) {
+			rule.prefix = NormalizePath(prefix)
+			f.ignores = append(f.ignores, rule)
+		}
+	}
+	return nil
+}
+
+// readGitignore reads the .gitignore file of a directory, returning nil when there is none
+func readGitignore(fs afero.Fs, dir string) ([]byte, error) {
+	gitignorePath := JoinPaths(dir, ".gitignore")
+	content, err := afero.ReadFile(fs, gitignorePath)
+	if err != nil {
+		if exists, _ := afero.Exists(fs, gitignorePath); !exists {
+			return nil, nil
+		}
+		return nil, WithFile(NewFSError("failed to read .gitignore file", err), gitignorePath)
+	}
+	return content, nil
+}
+
+// ignored reports whether the .gitignore rules ignore a path. As with git, the last matching
+// rule wins, and a negated rule re-includes the path.
+func (f *walkFilter) ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, rule := range f.ignores {
+		if rule.matches(name, isDir) {
+			ignored = !rule.negated
+		}
+	}
+	return ignored
+}
+
+// ignoreRule is a pattern of a .gitignore file
+type ignoreRule struct {
+	base     string   // The directory of the .gitignore file, or the root for the files of its parents
+	prefix   string   // The path of base relative to the directory of the .gitignore file, for its parents
+	segments []string // The pattern split into path segments
+	negated  bool     // Whether the pattern started with "!"
+	dirOnly  bool     // Whether the pattern ended with "/"
+}
+
+// parseGitignore parses the content of the .gitignore file of a directory.
+// Patterns without a slash other than a trailing one match at any depth below the directory.
+func parseGitignore(dir string, content []byte) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: NormalizePath(dir)}
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			rule.negated, line = true, rest
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if rest, ok := strings.CutSuffix(line, "/"); ok {
+			rule.dirOnly, line = true, rest
+		}
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		rule.segments = strings.Split(line, "/")
+		if !anchored {
+			rule.segments = append([]string{"**"}, rule.segments...)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches reports whether the rule matches a path, ignoring negation
+func (r ignoreRule) matches(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !isSubDir(r.base, name) || name == r.base {
+		return false
+	}
+
+	relative := name
+	if r.base != "." {
+		relative = strings.TrimPrefix(name, r.base+"/")
+	}
+	if r.prefix != "" {
+		relative = r.prefix + "/" + relative
+	}
+	return matchSegments(r.segments, strings.Split(relative, "/"))
+}
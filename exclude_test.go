@@ -0,0 +1,142 @@
+package goverhaul
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkFilterSkip(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	exclude, err := compileExcludes([]string{"internal/generated", "**/*_mock.go"})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		root     string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		"plain directory":           {root: ".", path: "internal/db", isDir: true, expected: false},
+		"vendor directory":          {root: ".", path: "vendor", isDir: true, expected: true},
+		"nested testdata directory": {root: ".", path: "internal/db/testdata", isDir: true, expected: true},
+		"node_modules directory":    {root: ".", path: "web/node_modules", isDir: true, expected: true},
+		"hidden directory":          {root: ".", path: ".git", isDir: true, expected: true},
+		"underscore directory":      {root: ".", path: "internal/_old", isDir: true, expected: true},
+		"hidden file":               {root: ".", path: "internal/.hidden.go", isDir: false, expected: true},
+		"underscore file":           {root: ".", path: "_old.go", isDir: false, expected: true},
+		"root directory":            {root: "testdata", path: "testdata", isDir: true, expected: false},
+		"current directory root":    {root: ".", path: ".", isDir: true, expected: false},
+		"excluded directory":        {root: ".", path: "internal/generated", isDir: true, expected: true},
+		"excluded file pattern":     {root: ".", path: "internal/db/store_mock.go", isDir: false, expected: true},
+		"file not excluded":         {root: ".", path: "internal/db/store.go", isDir: false, expected: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filter := newWalkFilter(memFs, test.root, exclude, false)
+			skip, err := filter.skip(test.path, test.isDir)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, skip)
+		})
+	}
+}
+
+func TestWalkFilterGitignore(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, ".gitignore", []byte("# build output\nbuild/\n*.pb.go\n!keep.pb.go\n/tmp\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "internal/.gitignore", []byte("legacy/**/old.go\n"), 0o644))
+
+	filter := newWalkFilter(memFs, ".", nil, true)
+	walk := func(path string, isDir bool) bool {
+		skip, err := filter.skip(path, isDir)
+		require.NoError(t, err)
+		return skip
+	}
+
+	require.False(t, walk(".", true))
+	assert.True(t, walk("build", true))
+	assert.True(t, walk("cmd/build", true))
+	assert.False(t, walk("cmd/build.go", false), "directory patterns do not match files")
+	assert.True(t, walk("api/service.pb.go", false))
+	assert.False(t, walk("api/keep.pb.go", false), "negated patterns re-include paths")
+	assert.True(t, walk("tmp", true))
+	assert.False(t, walk("internal/tmp", true), "anchored patterns match below the .gitignore directory only")
+
+	require.False(t, walk("internal", true))
+	assert.True(t, walk("internal/legacy/db/old.go", false))
+	assert.False(t, walk("legacy/db/old.go", false), "patterns apply below their .gitignore directory only")
+}
+
+func TestWalkFilterParentGitignore(t *testing.T) {
+	dir := t.TempDir()
+	osFs := afero.NewOsFs()
+	require.NoError(t, osFs.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, osFs.MkdirAll(filepath.Join(dir, "services/api"), 0o755))
+	require.NoError(t, afero.WriteFile(osFs, filepath.Join(dir, ".gitignore"), []byte("*.pb.go\n/services/api/tmp\n"), 0o644))
+	require.NoError(t, afero.WriteFile(osFs, filepath.Join(dir, "services/.gitignore"), []byte("build/\n!keep.pb.go\n"), 0o644))
+	t.Chdir(filepath.Join(dir, "services"))
+
+	filter := newWalkFilter(osFs, "api", nil, true)
+	walk := func(path string, isDir bool) bool {
+		skip, err := filter.skip(path, isDir)
+		require.NoError(t, err)
+		return skip
+	}
+
+	require.False(t, walk("api", true))
+	assert.True(t, walk("api/service.pb.go", false), "the .gitignore files of the parents apply")
+	assert.False(t, walk("api/keep.pb.go", false), "the rules of nested .gitignore files win")
+	assert.True(t, walk("api/build", true))
+	assert.True(t, walk("api/tmp", true), "anchored patterns match from the parent directory")
+	assert.False(t, walk("api/internal/tmp", true))
+
+	// Outside of a git repository, only the .gitignore files of the linted tree apply
+	require.NoError(t, osFs.Remove(filepath.Join(dir, ".git")))
+	filter = newWalkFilter(osFs, "api", nil, true)
+	require.False(t, walk("api", true))
+	assert.False(t, walk("api/service.pb.go", false))
+}
+
+func TestLintSkipsDirectories(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	source := []byte("package p\n\nimport \"unsafe\"\n")
+	for _, file := range []string{
+		"internal/db/db.go",
+		"internal/db/testdata/fixture.go",
+		"internal/vendor/lib/lib.go",
+		"internal/.cache/cached.go",
+		"internal/generated/api.go",
+		"internal/build/out.go",
+	} {
+		require.NoError(t, afero.WriteFile(memFs, file, source, 0o644))
+	}
+	require.NoError(t, afero.WriteFile(memFs, "internal/.gitignore", []byte("build/\n"), 0o644))
+
+	cfg := Config{
+		Modfile:   "go.mod",
+		Exclude:   []string{"internal/generated"},
+		Gitignore: true,
+		Rules: []Rule{
+			{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}},
+		},
+	}
+	linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 1)
+	assert.Equal(t, "internal/db/db.go", violations.Violations[0].File)
+	assert.Equal(t, 1, linter.Summary().FilesScanned)
+}
+
+func TestNewLinterInvalidExclude(t *testing.T) {
+	_, err := NewLinter(Config{Exclude: []string{"internal/[a"}}, nil, afero.NewMemMapFs())
+	assert.ErrorIs(t, err, ErrConfig)
+}
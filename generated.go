@@ -0,0 +1,39 @@
+package goverhaul
+
+import "cmp"
+
+// GeneratedMode controls how generated files, marked with the standard
+// "// Code generated ... DO NOT EDIT." comment, are linted
+type GeneratedMode string
+
+const (
+	// GeneratedSkip does not lint generated files
+	GeneratedSkip GeneratedMode = "skip"
+	// GeneratedInclude lints generated files like the other files
+	GeneratedInclude GeneratedMode = "include"
+	// GeneratedRules lints generated files against the rules of the generated policy only
+	GeneratedRules GeneratedMode = "rules"
+)
+
+// GeneratedPolicy is how generated files are linted
+type GeneratedPolicy struct {
+	Mode GeneratedMode `yaml:"mode" mapstructure:"mode"` // Default: skip
+	// Rules replace the configured rules for generated files in rules mode
+	Rules []Rule `yaml:"rules" mapstructure:"rules"`
+}
+
+// validateGeneratedPolicy checks the mode of the generated policy
+func validateGeneratedPolicy(policy GeneratedPolicy) error {
+	switch policy.Mode {
+	case "", GeneratedSkip, GeneratedInclude, GeneratedRules:
+		return nil
+	default:
+		return WithDetails(NewConfigError("invalid generated mode "+string(policy.Mode), nil),
+			"generated mode must be one of: skip, include, rules")
+	}
+}
+
+// mode returns the mode of the policy, defaulting to GeneratedSkip
+func (p GeneratedPolicy) mode() GeneratedMode {
+	return cmp.Or(p.Mode, GeneratedSkip)
+}
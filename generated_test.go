@@ -0,0 +1,88 @@
+package goverhaul
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintGeneratedFiles(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"internal/api/handler.go": "package api\n\nimport \"github.com/acme/sql\"\n",
+		"internal/api/api.pb.go": "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n\n" +
+			"import (\n\t\"github.com/acme/sql\"\n\t\"google.golang.org/protobuf/proto\"\n)\n",
+		"internal/api/mock.go": "// Code generated by MockGen. DO NOT EDIT.\n\npackage api\n\nimport \"github.com/golang/mock/gomock\"\n",
+		// The marker has to be exact, and before the package clause
+		"internal/api/notes.go": "// Code generated by hand, please edit.\n\npackage api\n\nimport \"github.com/acme/sql\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+
+	rules := []Rule{{Path: "internal/api", Prohibited: []ProhibitedPkg{{Name: "github.com/acme/sql"}}}}
+
+	tests := map[string]struct {
+		generated GeneratedPolicy
+		expected  []string // file:import of the violations
+		skipped   int
+	}{
+		"generated files are skipped by default": {
+			expected: []string{"internal/api/handler.go:github.com/acme/sql", "internal/api/notes.go:github.com/acme/sql"},
+			skipped:  2,
+		},
+		"include generated files": {
+			generated: GeneratedPolicy{Mode: GeneratedInclude},
+			expected: []string{
+				"internal/api/api.pb.go:github.com/acme/sql",
+				"internal/api/handler.go:github.com/acme/sql",
+				"internal/api/notes.go:github.com/acme/sql",
+			},
+		},
+		"separate rules for generated files": {
+			generated: GeneratedPolicy{
+				Mode:  GeneratedRules,
+				Rules: []Rule{{Path: "internal/api", Prohibited: []ProhibitedPkg{{Name: "github.com/golang/mock/gomock"}}}},
+			},
+			expected: []string{
+				"internal/api/handler.go:github.com/acme/sql",
+				"internal/api/mock.go:github.com/golang/mock/gomock",
+				"internal/api/notes.go:github.com/acme/sql",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{Modfile: "go.mod", Rules: rules, Generated: test.generated}
+			linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+			require.NoError(t, err)
+
+			violations, err := linter.Lint("internal")
+			require.ErrorIs(t, err, ErrLint)
+
+			var actual []string
+			for _, v := range violations.Violations {
+				actual = append(actual, v.File+":"+v.Import)
+			}
+			assert.Equal(t, test.expected, actual)
+			assert.Equal(t, test.skipped, linter.Summary().GeneratedSkipped)
+			assert.Equal(t, 4, linter.Summary().FilesScanned)
+		})
+	}
+}
+
+func TestInvalidGeneratedPolicy(t *testing.T) {
+	_, err := NewLinter(Config{Generated: GeneratedPolicy{Mode: "lint"}}, nil, afero.NewMemMapFs())
+	assert.ErrorIs(t, err, ErrConfig)
+
+	_, err = NewLinter(Config{
+		Rules:     []Rule{{ID: "api", Path: "internal/api"}},
+		Generated: GeneratedPolicy{Mode: GeneratedRules, Rules: []Rule{{ID: "api", Path: "internal/api"}}},
+	}, nil, afero.NewMemMapFs())
+	assert.ErrorIs(t, err, ErrConfig, "rule ids are unique across generated rules")
+}
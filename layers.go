@@ -0,0 +1,103 @@
+package goverhaul
+
+import "fmt"
+
+// Layer is a named group of package paths in a layered architecture
+type Layer struct {
+	Name  string   `yaml:"name" mapstructure:"name"`
+	Paths []string `yaml:"paths" mapstructure:"paths"` // Package path patterns belonging to the layer
+}
+
+// LayeringMode controls which lower layers a layer may import
+type LayeringMode string
+
+const (
+	// LayeringRelaxed lets a layer import any layer below it
+	LayeringRelaxed LayeringMode = "relaxed"
+	// LayeringStrict lets a layer import the layer directly below it only
+	LayeringStrict LayeringMode = "strict"
+)
+
+// validateLayers checks that the layers are well-formed
+func validateLayers(layers []Layer, mode LayeringMode) error {
+	switch mode {
+	case "", LayeringRelaxed, LayeringStrict:
+	default:
+		return WithDetails(NewConfigError("invalid layering mode "+string(mode), nil),
+			"layering must be one of: relaxed, strict")
+	}
+
+	names := make(map[string]bool, len(layers))
+	for i, layer := range layers {
+		if layer.Name == "" {
+			return NewConfigError(fmt.Sprintf("layer #%d has no name", i+1), nil)
+		}
+		if names[layer.Name] {
+			return NewConfigError("duplicate layer "+layer.Name, nil)
+		}
+		names[layer.Name] = true
+
+		if len(layer.Paths) == 0 {
+			return NewConfigError("layer "+layer.Name+" has no paths", nil)
+		}
+		for _, path := range layer.Paths {
+			if hasWildcard(path) {
+				if _, err := compilePattern(path); err != nil {
+					return WithDetails(err, "Layer: "+layer.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// layerRules compiles the layers, ordered from top to bottom, into rules that prohibit
+// each layer from importing the layers above it and, in strict mode, the layers more than one level below it.
+func layerRules(layers []Layer, mode LayeringMode) []Rule {
+	rules := make([]Rule, 0)
+	for i, source := range layers {
+		var prohibited []ProhibitedPkg
+		for j, target := range layers {
+			var cause string
+			switch {
+			case j < i:
+				cause = fmt.Sprintf("Layer %s must not depend on layer %s above it", source.Name, target.Name)
+			case j > i+1 && mode == LayeringStrict:
+				cause = fmt.Sprintf("Layer %s must not skip layer %s to depend on layer %s",
+					source.Name, layers[i+1].Name, target.Name)
+			default:
+				continue
+			}
+
+			for _, path := range target.Paths {
+				prohibited = append(prohibited, ProhibitedPkg{
+					Name:  layerImportPattern(path),
+					Cause: cause,
+				})
+			}
+		}
+
+		if len(prohibited) == 0 {
+			continue
+		}
+		for _, path := range source.Paths {
+			rules = append(rules, Rule{
+				Path:       path,
+				Prohibited: prohibited,
+			})
+		}
+	}
+
+	return rules
+}
+
+// layerImportPattern turns a layer path into an import list entry matching
+// the layer's packages and every package below them
+func layerImportPattern(path string) string {
+	path = NormalizePath(path)
+	if hasWildcard(path) {
+		return path + "/**"
+	}
+	return path + "/..."
+}
@@ -0,0 +1,101 @@
+package goverhaul
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLayers() []Layer {
+	return []Layer{
+		{Name: "api", Paths: []string{"internal/api"}},
+		{Name: "application", Paths: []string{"internal/application"}},
+		{Name: "domain", Paths: []string{"internal/domain", "internal/*/model"}},
+	}
+}
+
+func TestLayerRules(t *testing.T) {
+	t.Run("relaxed layering prohibits upward imports only", func(t *testing.T) {
+		rules := layerRules(testLayers(), LayeringRelaxed)
+
+		require.Len(t, rules, 3)
+		assert.Equal(t, "internal/application", rules[0].Path)
+		assert.Equal(t, []ProhibitedPkg{
+			{Name: "internal/api/...", Cause: "Layer application must not depend on layer api above it"},
+		}, rules[0].Prohibited)
+
+		assert.Equal(t, "internal/domain", rules[1].Path)
+		assert.Equal(t, "internal/*/model", rules[2].Path)
+		assert.Equal(t, []ProhibitedPkg{
+			{Name: "internal/api/...", Cause: "Layer domain must not depend on layer api above it"},
+			{Name: "internal/application/...", Cause: "Layer domain must not depend on layer application above it"},
+		}, rules[2].Prohibited)
+	})
+
+	t.Run("strict layering also prohibits skipping layers", func(t *testing.T) {
+		rules := layerRules(testLayers(), LayeringStrict)
+
+		require.Len(t, rules, 4)
+		assert.Equal(t, "internal/api", rules[0].Path)
+		assert.Equal(t, []ProhibitedPkg{
+			{Name: "internal/domain/...", Cause: "Layer api must not skip layer application to depend on layer domain"},
+			{Name: "internal/*/model/**", Cause: "Layer api must not skip layer application to depend on layer domain"},
+		}, rules[0].Prohibited)
+	})
+}
+
+func TestValidateLayers(t *testing.T) {
+	tests := map[string]struct {
+		layers []Layer
+		mode   LayeringMode
+	}{
+		"invalid mode":     {layers: testLayers(), mode: "loose"},
+		"missing name":     {layers: []Layer{{Paths: []string{"internal"}}}},
+		"duplicate name":   {layers: []Layer{{Name: "a", Paths: []string{"a"}}, {Name: "a", Paths: []string{"b"}}}},
+		"missing paths":    {layers: []Layer{{Name: "a"}}},
+		"invalid patterns": {layers: []Layer{{Name: "a", Paths: []string{"internal/[a-"}}}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, validateLayers(test.layers, test.mode), ErrConfig)
+		})
+	}
+
+	assert.NoError(t, validateLayers(testLayers(), LayeringStrict))
+}
+
+func TestLintLayers(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"go.mod":                          "module example.com\n\ngo 1.20\n",
+		"internal/api/api.go":             "package api\n\nimport _ \"example.com/internal/domain\"\n",
+		"internal/application/app.go":     "package application\n\nimport _ \"example.com/internal/domain/user\"\n",
+		"internal/domain/user/user.go":    "package user\n\nimport _ \"example.com/internal/application\"\n",
+		"internal/orders/model/order.go":  "package model\n\nimport _ \"example.com/internal/domain/user\"\n",
+		"internal/orders/service/svc.go":  "package service\n\nimport _ \"example.com/internal/api\"\n",
+		"internal/domain/shared/types.go": "package shared\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+
+	cfg := Config{
+		Modfile:  "go.mod",
+		Layers:   testLayers(),
+		Layering: LayeringStrict,
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 2)
+
+	assert.Equal(t, "internal/api/api.go", violations.Violations[0].File)
+	assert.Equal(t, "Layer api must not skip layer application to depend on layer domain", violations.Violations[0].Cause)
+	assert.Equal(t, "internal/domain/user/user.go", violations.Violations[1].File)
+	assert.Equal(t, "Layer domain must not depend on layer application above it", violations.Violations[1].Cause)
+}
@@ -1,14 +1,22 @@
 package goverhaul
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
 	"go/parser"
 	"go/token"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/gophersatwork/granular"
 	"github.com/spf13/afero"
 	"golang.org/x/mod/modfile"
 )
@@ -20,9 +28,19 @@ var (
 )
 
 type Goverhaul struct {
-	cfg    Config
-	logger *slog.Logger
-	cache  *LintCache
+	cfg   Config
+	rules []*compiledRule
+	// generatedRules replace rules for generated files, in GeneratedRules mode
+	generatedRules []*compiledRule
+	logger         *slog.Logger
+	cache          *LintCache
+	graph          *componentGraph  // nil unless no_cycles is configured
+	baseline       *Baseline        // nil unless a baseline is configured
+	modules        *moduleIndex     // Module names of the go.mod files seen during the current run
+	exclude        []pathPattern    // Compiled Config.Exclude patterns
+	packages       *packageIndex    // The packages loaded for the current run, in packages analysis
+	deps           *dependencyGraph // The imports of the packages read during the current run, for transitive rules
+	summary        RunSummary
 
 	fs afero.Fs
 }
@@ -34,31 +52,203 @@ func NewLinter(cfg Config, logger *slog.Logger, fs afero.Fs) (*Goverhaul, error)
 		logger: ensureLogger(logger),
 	}
 
+	if _, err := ParseSeverity(string(cfg.FailOn)); err != nil {
+		return nil, WithDetails(err, "fail_on must be one of: error, warning, info, none")
+	}
+	if err := validateLayers(cfg.Layers, cfg.Layering); err != nil {
+		return nil, err
+	}
+	if err := validateTestsPolicy(cfg.Tests); err != nil {
+		return nil, err
+	}
+	if err := validateGeneratedPolicy(cfg.Generated); err != nil {
+		return nil, err
+	}
+	if err := validateAnalysisMode(cfg.Analysis, fs); err != nil {
+		return nil, err
+	}
+	if err := validateBuildContexts(cfg.BuildContexts, append(cfg.EffectiveRules(), cfg.Generated.Rules...)); err != nil {
+		return nil, err
+	}
+
+	// Compile the rule patterns once for the whole run
+	ids := make(map[string]bool)
+	rules, err := compileRules(cfg.EffectiveRules(), cfg.Tests, ids)
+	if err != nil {
+		return nil, err
+	}
+	linter.rules = rules
+	if cfg.Generated.mode() == GeneratedRules {
+		rules, err := compileRules(cfg.Generated.Rules, cfg.Tests, ids)
+		if err != nil {
+			return nil, WithDetails(err, "Generated rules")
+		}
+		linter.generatedRules = rules
+	}
+
+	exclude, err := compileExcludes(cfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	linter.exclude = exclude
+
+	if len(cfg.NoCycles) > 0 {
+		graph, err := newComponentGraph(cfg.NoCycles)
+		if err != nil {
+			return nil, err
+		}
+		linter.graph = graph
+	}
+
+	if cfg.Baseline != "" {
+		baseline, err := LoadBaseline(fs, cfg.Baseline)
+		if err != nil {
+			return nil, err
+		}
+		linter.baseline = baseline
+	}
+
 	// Load cache for incremental analysis if enabled
-	var cache LintCache
-	var err error
 	if cfg.Incremental {
-		cache, err = linter.initializeCache(cfg.CacheFile)
-		linter.cache = &cache
+		cache, err := linter.initializeCache(cfg.CacheFile)
 		if err != nil {
 			return nil, err
 		}
+		linter.cache = cache
 	}
 
 	return linter, nil
 }
 
-// Lint analyzes Go files in the given path for import rule violations
+// compileRules compiles rules with the global tests policy, checking that their ids are unique
+// among the ones already seen
+func compileRules(rules []Rule, tests *TestsPolicy, ids map[string]bool) ([]*compiledRule, error) {
+	compiledRules := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err == nil {
+			err = compiled.compileTests(cmp.Or(rule.Tests, tests))
+		}
+		if err != nil {
+			return nil, WithDetails(err, "Rule path: "+rule.Path)
+		}
+		if err := claimRuleIDs(rule, ids); err != nil {
+			return nil, err
+		}
+		compiledRules = append(compiledRules, compiled)
+	}
+	return compiledRules, nil
+}
+
+// claimRuleIDs adds the explicit ids of a rule, of its allow-list and of its prohibited entries
+// to the ids already seen, checking that they are unique
+func claimRuleIDs(rule Rule, ids map[string]bool) error {
+	var claimed []string
+	if rule.ID != "" {
+		claimed = append(claimed, rule.ID, allowedRuleID(rule))
+	}
+	for _, prohibited := range rule.Prohibited {
+		if prohibited.ID != "" {
+			claimed = append(claimed, prohibited.ID)
+		}
+	}
+
+	for _, id := range claimed {
+		if ids[id] {
+			return NewConfigError("duplicate rule id "+id, nil)
+		}
+		ids[id] = true
+	}
+	return nil
+}
+
+// Lint analyzes Go files in the given path for import rule violations.
+// When violations at or above the configured FailOn severity are found, the
+// violations are returned together with an error wrapping ErrLint. When Go files
+// cannot be parsed, the violations of the other files are returned together with
+// an error wrapping ErrParse instead.
 func (g *Goverhaul) Lint(path string) (*LintViolations, error) {
+	start := time.Now()
+	g.summary = RunSummary{Version: toolVersion()}
+	if err := g.startRun(path); err != nil {
+		return nil, err
+	}
+	if g.cache != nil {
+		if err := g.setCacheFingerprint(); err != nil {
+			return nil, err
+		}
+	}
+	if g.graph != nil {
+		g.graph.edges = make(map[string]map[string]componentEdge)
+	}
+
 	// Walk the file system and check each file
 	violations, err := g.walkAndLint(path)
+	g.summary.Duration = time.Since(start)
 	if err != nil {
 		return nil, handleWalkError(err, path)
 	}
+	if g.cache != nil {
+		run := CacheRun{
+			HitsClean:      g.summary.CacheHitsClean,
+			HitsViolations: g.summary.CacheHitsViolations,
+			Misses:         g.summary.CacheMisses,
+			Time:           start,
+		}
+		if err := g.cache.recordRun(run); err != nil {
+			g.logger.Warn("Failed to record cache statistics", "error", err)
+		}
+	}
+	if g.graph != nil {
+		for _, v := range g.graph.violations() {
+			violations.Add(v)
+		}
+	}
+	if g.baseline != nil {
+		remaining, fixed := g.baseline.Filter(violations, NormalizePath(path))
+		g.summary.Baselined = len(violations.Violations) - len(remaining.Violations)
+		g.summary.FixedBaselineEntries = fixed
+		violations = remaining
+	}
+	violations.Sort()
+
+	if len(g.summary.UnparsedFiles) > 0 {
+		return violations, WithDetails(NewParseError(fmt.Sprintf("%d Go files could not be parsed", len(g.summary.UnparsedFiles)), nil),
+			"Files: "+strings.Join(g.summary.UnparsedFiles, ", "))
+	}
+	if failing := violations.CountAtLeast(g.failOn()); failing > 0 {
+		return violations, WithDetails(NewError(ErrLint.Error(), ErrLint),
+			fmt.Sprintf("%d violations at or above %s severity", failing, g.failOn()))
+	}
 
 	return violations, nil
 }
 
+// startRun loads the modules and the packages of the linted path
+func (g *Goverhaul) startRun(path string) error {
+	g.modules = newModuleIndex(g.fs, g.cfg.Modfile)
+	if err := g.modules.loadWorkspace(path); err != nil {
+		return err
+	}
+	if err := g.loadPackages(path); err != nil {
+		return err
+	}
+	g.deps = newDependencyGraph()
+	return nil
+}
+
+// Summary returns the summary of the last Lint run
+func (g *Goverhaul) Summary() RunSummary {
+	return g.summary
+}
+
+// failOn returns the configured fail-on threshold, defaulting to SeverityError.
+// NewLinter has already checked that it parses.
+func (g *Goverhaul) failOn() Severity {
+	failOn, _ := ParseSeverity(string(g.cfg.FailOn))
+	return failOn
+}
+
 // ensureLogger creates a default logger if none is provided
 func ensureLogger(logger *slog.Logger) *slog.Logger {
 	if logger == nil {
@@ -70,135 +260,371 @@ func ensureLogger(logger *slog.Logger) *slog.Logger {
 }
 
 // initializeCache sets up the cache for incremental analysis
-func (g *Goverhaul) initializeCache(cachePath string) (LintCache, error) {
+func (g *Goverhaul) initializeCache(cachePath string) (*LintCache, error) {
 	g.logger.Info("Using incremental analysis", "cache_file", cachePath)
 
-	gCache, err := granular.New(cachePath, granular.WithFs(g.fs))
+	cache, err := NewCacheWithFs(cachePath, g.fs)
 	if err != nil {
-		return LintCache{}, NewCacheError("failed to load cache", err)
+		return nil, NewCacheError("failed to load cache", err)
+	}
+	cache.modulePath = func(path string) string {
+		return g.moduleOf(path).path
 	}
-	return LintCache{gCache: gCache}, nil
+	return cache, nil
 }
 
-// walkAndLint walks the file system and lints each Go file
-func (g *Goverhaul) walkAndLint(path string) (*LintViolations, error) {
-	violations := NewLintViolations()
+// setCacheFingerprint sets the fingerprint of the cache entries of the current run
+func (g *Goverhaul) setCacheFingerprint() error {
+	fingerprint, err := cacheFingerprint(g.cfg, g.fs, g.modules.workspacePaths())
+	if err != nil {
+		return err
+	}
+	cleared, err := g.cache.SetFingerprint(fingerprint)
+	if err != nil {
+		return err
+	}
+	if cleared {
+		g.logger.Info("Cleared the cache, the configuration, the workspace or the goverhaul version changed",
+			"cache_file", g.cfg.CacheFile)
+	}
+	return nil
+}
 
-	// Use afero.Walk instead of filepath.Walk
-	err := afero.Walk(g.fs, path, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return WithDetails(WithFile(NewFSError("error accessing path", err), path),
-				"Check if the path exists and you have permission to access it")
-		}
+// jobs returns the number of files analyzed concurrently, defaulting to GOMAXPROCS
+func (g *Goverhaul) jobs() int {
+	if g.cfg.Jobs > 0 {
+		return g.cfg.Jobs
+	}
+	return runtime.GOMAXPROCS(0)
+}
 
-		if !isGoFileFs(info) {
-			return nil
-		}
+// fileResult is the outcome of the analysis of a single Go file
+type fileResult struct {
+	path         string
+	parseErr     error // Why the file could not be parsed, if it could not
+	violations   []LintViolation
+	suppressions []Suppression // The suppressions applied to the file
+	rules        []string      // The names of the rules checked against the file
+	imports      []importRef   // Only set when the component graph needs them
+	module       goModule      // The module of the file, along with imports
+	generated    bool          // Whether the file is a generated file that was skipped
+	cacheStatus  CacheStatus   // The outcome of the cache lookup, in incremental mode
+	// dependent is set when transitive rules were checked: the violations depend on the
+	// packages the file imports, so they are not cached
+	dependent bool
+	// lintable is set when the rules were checked: the file was parsed, is built in a
+	// build context and is not a skipped generated file. Only then are results cached.
+	lintable bool
+}
+
+// walkAndLint walks the file system and lints each Go file.
+// The walker feeds the Go files to a pool of workers analyzing them concurrently, and
+// the results are merged by the calling goroutine, which owns the violations and the summary.
+func (g *Goverhaul) walkAndLint(path string) (*LintViolations, error) {
+	files := make(chan string)
+	results := make(chan fileResult)
+
+	var walkErr error
+	go func() {
+		defer close(files)
+		filter := newWalkFilter(g.fs, path, g.exclude, g.cfg.Gitignore)
+		// Use afero.Walk instead of filepath.Walk
+		walkErr = afero.Walk(g.fs, path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return WithDetails(WithFile(NewFSError("error accessing path", err), path),
+					"Check if the path exists and you have permission to access it")
+			}
 
-		// Check if we can skip this file based on cache
-		if g.cfg.Incremental {
-			cachedViolations := g.hasCachedViolations(path)
-			if len(cachedViolations) > 0 {
-				for _, v := range cachedViolations {
-					violations.Add(v)
+			skip, err := filter.skip(path, info.IsDir())
+			if err != nil {
+				return err
+			}
+			if skip {
+				if info.IsDir() {
+					// Skip the whole directory instead of walking and filtering its files
+					g.logger.Debug("Skipping directory", "path", path)
+					return filepath.SkipDir
 				}
 				return nil
 			}
+
+			if isGoFileFs(info) {
+				files <- path
+			}
+			return nil
+		})
+	}()
+
+	var workers sync.WaitGroup
+	for range g.jobs() {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for file := range files {
+				results <- g.analyzeFile(file)
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	violations := NewLintViolations()
+	evaluated := make(map[string]bool) // Names of the rules checked against the files
+	for result := range results {
+		g.collect(result, violations)
+		for _, rule := range result.rules {
+			evaluated[rule] = true
 		}
-		err = g.lintFile(path, violations)
-		return err
-	})
-	if err != nil {
-		return nil, err
 	}
+	// All workers are done, so the walker has returned
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	g.summary.RulesEvaluated = len(evaluated)
+	slices.Sort(g.summary.UnparsedFiles)
+	slices.SortFunc(g.summary.Suppressions, func(a, b Suppression) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
 	return violations, nil
 }
 
+// collect merges the result of a file into the violations and the run summary
+func (g *Goverhaul) collect(result fileResult, violations *LintViolations) {
+	g.summary.FilesScanned++
+	if result.parseErr != nil {
+		g.summary.UnparsedFiles = append(g.summary.UnparsedFiles, NormalizePath(result.path))
+	}
+	if result.generated {
+		g.summary.GeneratedSkipped++
+	}
+	if g.cache != nil {
+		switch result.cacheStatus {
+		case CacheHitClean:
+			g.summary.FilesCached++
+			g.summary.CacheHitsClean++
+		case CacheHitViolations:
+			g.summary.FilesCached++
+			g.summary.CacheHitsViolations++
+		default:
+			g.summary.CacheMisses++
+		}
+	}
+	g.summary.Suppressions = append(g.summary.Suppressions, result.suppressions...)
+	if g.graph != nil && result.imports != nil {
+		g.graph.addImports(result.path, result.module, g.modules.workspace, result.imports)
+	}
+	for _, v := range result.violations {
+		violations.Add(v)
+	}
+}
+
 // isGoFileFs checks if the file is a Go source file using afero.Fs.FileInfo
 func isGoFileFs(info os.FileInfo) bool {
 	return !info.IsDir() && strings.HasSuffix(info.Name(), ".go")
 }
 
-// hasCachedViolations checks if a file can be skipped based on cache
-func (g *Goverhaul) hasCachedViolations(path string) []LintViolation {
-	var cachedViolations LintViolations
-	cachedViolations, err := g.cache.HasEntry(path)
-	if err != nil {
-		if errors.Is(err, ErrEntryNotFound) || errors.Is(err, ErrReadingCachedViolations) {
-			// just log and continue the linting. LintCache checking should not halt the main operation.
-			g.logger.Warn("Error checking file change status", "path", path, "error", err)
+// analyzeFile lints a Go file, serving its violations from the cache when possible.
+// It is called concurrently by the workers of walkAndLint.
+func (g *Goverhaul) analyzeFile(goFilePath string) fileResult {
+	if g.cache == nil {
+		return g.lintFile(goFilePath)
+	}
+
+	status, cached := g.cachedViolations(goFilePath)
+	if status == CacheMiss {
+		result := g.lintFile(goFilePath)
+		if result.lintable && !result.dependent {
+			g.updateCache(goFilePath, cachedFile{violations: result.violations, suppressions: result.suppressions, rules: result.rules})
 		}
+		return result
+	}
+	result := fileResult{
+		path:         goFilePath,
+		violations:   cached.violations,
+		suppressions: cached.suppressions,
+		rules:        cached.rules,
+		cacheStatus:  status,
 	}
+	if g.graph != nil && inComponentGraph(goFilePath) {
+		result.imports, result.module = g.graphImports(goFilePath)
+	}
+	return result
+}
 
-	return cachedViolations.Violations
+// cachedViolations looks a file up in the cache, returning its cached violations, suppressions and rules
+func (g *Goverhaul) cachedViolations(path string) (CacheStatus, cachedFile) {
+	status, cached, err := g.cache.lookup(path)
+	if err != nil {
+		// just log and continue the linting. LintCache checking should not halt the main operation.
+		g.logger.Warn("Error reading cached violations", "path", path, "error", err)
+	}
+	g.logger.Debug("Cache lookup", "path", path, "status", status)
+	return status, cached
 }
 
 // lintFile lints a single Go file
-func (g *Goverhaul) lintFile(goFilePath string, violations *LintViolations) error {
+func (g *Goverhaul) lintFile(goFilePath string) fileResult {
+	result := fileResult{path: goFilePath}
+
 	g.logger.Debug("Analyzing file", "path", goFilePath)
-	imports, err := g.getImports(goFilePath)
+	file, err := g.parseFile(goFilePath)
 	if err != nil {
 		g.logger.Error("Could not parse file", "path", goFilePath, "error", err)
-		// Continue with other files even if one fails to parse
-		return nil
+		// Continue with other files even if one fails to parse, Lint reports the failure at the end
+		result.parseErr = err
+		return result
+	}
+
+	rules := g.rules
+	if file.generated {
+		switch g.cfg.Generated.mode() {
+		case GeneratedSkip:
+			g.logger.Debug("Skipping generated file", "path", goFilePath)
+			result.generated = true
+			return result
+		case GeneratedRules:
+			rules = g.generatedRules
+		}
+	}
+
+	imports := file.imports
+	g.logger.Debug("Imports found", "path", goFilePath, "imports", importPaths(imports))
+
+	contexts := g.fileContexts(goFilePath, file)
+	if len(g.cfg.BuildContexts) > 0 && len(contexts) == 0 {
+		g.logger.Debug("File is not built in any build context", "path", goFilePath)
+		return result
 	}
 
-	g.logger.Debug("Imports found", "path", goFilePath, "imports", imports)
+	imports, module := g.resolveImports(goFilePath, imports, g.moduleOf(goFilePath))
+	if g.graph != nil && inComponentGraph(goFilePath) {
+		result.imports, result.module = imports, module
+	}
 
-	for _, rule := range g.cfg.Rules {
-		g.logger.Debug("Checking rule", "path", goFilePath, "rule_path", rule.Path, "applies", ruleAppliesToPath(rule, goFilePath))
-		if !ruleAppliesToPath(rule, goFilePath) {
+	location := locateFile(goFilePath, module)
+	testPackage := testPackageOf(goFilePath, file.pkg)
+	fileViolations := make([]LintViolation, 0)
+	var chains []importChain // The packages reached through the imports, for the first transitive rule
+	for _, rule := range rules {
+		applies := rule.appliesTo(location)
+		g.logger.Debug("Checking rule", "path", goFilePath, "rule_path", rule.rule.Path, "applies", applies)
+		if !applies {
+			continue
+		}
+		checked := rule.forFile(testPackage)
+		if checked == nil {
+			continue
+		}
+		ruleContexts := rule.inContexts(contexts)
+		if len(g.cfg.BuildContexts) > 0 && len(ruleContexts) == 0 {
 			continue
 		}
+		result.rules = append(result.rules, ruleName(checked.rule))
 
-		// Join the directory of the file being linted with the modfile name
-		modfilePath := JoinPaths(DirPath(goFilePath), g.cfg.Modfile)
-		fileViolations := g.checkImports(goFilePath, imports, rule, modfilePath)
-		for _, v := range fileViolations {
-			violations.Add(v)
+		violations := g.checkImports(goFilePath, imports, checked, module.path)
+		if checked.transitive {
+			if chains == nil {
+				chains = g.importChains(imports, module)
+			}
+			violations = append(violations, g.checkTransitive(goFilePath, chains, checked, module.path)...)
+			result.dependent = true
+		}
+		for i := range violations {
+			violations[i].Contexts = ruleContexts
 		}
+		fileViolations = append(fileViolations, violations...)
+	}
+
+	result.violations, result.suppressions = applySuppressions(fileViolations, file.suppressions)
+	result.lintable = true
+	return result
+}
 
-		// Update cache if incremental analysis is enabled
-		if g.cfg.Incremental {
-			g.updateCache(goFilePath, fileViolations)
+// fileContexts returns the names of the configured build contexts a file is built in
+func (g *Goverhaul) fileContexts(goFilePath string, file parsedFile) []string {
+	var contexts []string
+	for _, context := range g.cfg.BuildContexts {
+		if context.matches(goFilePath, file.constraint) {
+			contexts = append(contexts, context.contextName())
 		}
 	}
+	return contexts
+}
+
+// graphImports returns the imports of a file served from the cache, for the component graph
+func (g *Goverhaul) graphImports(goFilePath string) ([]importRef, goModule) {
+	imports, _, err := g.getImports(goFilePath)
+	if err != nil {
+		g.logger.Error("Could not parse file", "path", goFilePath, "error", err)
+		return nil, goModule{}
+	}
+	return g.resolveImports(goFilePath, imports, g.moduleOf(goFilePath))
+}
 
-	return nil
+// moduleOf returns the module a Go file belongs to: the one of the nearest go.mod
+// in the directory of the file or its parents
+func (g *Goverhaul) moduleOf(goFilePath string) goModule {
+	if g.modules == nil {
+		// Lint has not started, e.g. when linting a single file
+		g.modules = newModuleIndex(g.fs, g.cfg.Modfile)
+	}
+	return g.modules.moduleOf(DirPath(goFilePath))
+}
+
+// fileLocation is the directory of a linted file, resolved once for all rules
+type fileLocation struct {
+	dir    string // The normalized directory of the file
+	absDir string // The absolute directory of the file, empty when dir is already absolute
+	// The directory of the file relative to the root of its module, empty when it has none.
+	// Relative rule paths match it wherever the linted path is, absolute or below the module root.
+	moduleDir string
+}
+
+// locateFile resolves the directory of a linted file of the given module
+func locateFile(filePath string, module goModule) fileLocation {
+	location := fileLocation{dir: DirPath(filePath)}
+	if !IsAbsPath(location.dir) {
+		location.absDir = DirPath(AbsPath(filePath))
+	}
+	if module.root != "" {
+		rel, err := filepath.Rel(AbsPath(module.root), cmp.Or(location.absDir, location.dir))
+		if rel = NormalizePath(rel); err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			location.moduleDir = rel
+		}
+	}
+	return location
 }
 
-// ruleAppliesToPath checks if a rule applies to a given file path
-func ruleAppliesToPath(rule Rule, filePath string) bool {
-	rulePath := NormalizePath(rule.Path)
-	currentDir := DirPath(filePath)
+// ruleAppliesToDir checks if a rule with the given normalized path applies to a file location
+func ruleAppliesToDir(rulePath string, location fileLocation) bool {
+	currentDir := location.dir
 
 	// Convert paths to absolute if needed
-	if !IsAbsPath(rulePath) && !IsAbsPath(currentDir) {
-		absPath := AbsPath(filePath)
-		absDir := DirPath(absPath)
+	if !IsAbsPath(rulePath) && location.absDir != "" {
+		absDir := location.absDir
 
 		// If the current directory is not absolute (relative to working dir)
 		// and the rule path is also relative (to project root)
 		// then we need to check if the absolute path ends with the rule path
-		// or if it's a subdirectory of the rule path
-		if strings.HasSuffix(absDir, rulePath) || IsSubPath(rulePath, absDir) {
+		// segments or if it's a subdirectory of the rule path
+		if strings.HasSuffix(absDir, "/"+rulePath) || isSubDir(rulePath, absDir) {
 			return true
 		}
 	}
 
 	// Check if the current directory matches the rule path exactly or is a subdirectory
-	return currentDir == rulePath || IsSubPath(rulePath, currentDir)
+	if currentDir == rulePath || isSubDir(rulePath, currentDir) {
+		return true
+	}
+	return location.moduleDir != "" && !IsAbsPath(rulePath) && isSubDir(rulePath, location.moduleDir)
 }
 
-// updateCache updates the cache with file violations
-func (g *Goverhaul) updateCache(path string, fileViolations []LintViolation) {
-	var err error
-	if len(fileViolations) == 0 {
-		err = g.cache.AddFile(path)
-	} else {
-		err = g.cache.AddFileWithViolations(path, fileViolations)
-	}
-	if err != nil {
+// updateCache updates the cache with the outcome of the linting of a file
+func (g *Goverhaul) updateCache(path string, file cachedFile) {
+	if err := g.cache.addEntry(path, file); err != nil {
 		g.logger.Warn("Failed to update cache for file", "path", path, "error", err)
 	}
 }
@@ -239,137 +665,267 @@ func getModuleName(fs afero.Fs, modfilePath string) (string, error) {
 	return modulePath, nil
 }
 
-// getImportsWithFs gets imports from a Go file using afero.Fs
-func (g *Goverhaul) getImports(path string) ([]string, error) {
+// importRef is an import path together with its location in the source file
+type importRef struct {
+	Path  string
+	Pos   token.Position // Start of the import path literal
+	End   token.Position // Position just past the end of the import path literal
+	Group string         // The import group selector resolved by package analysis, empty otherwise
+}
+
+// importPaths returns the import paths of the given import references, nil when there are none
+func importPaths(imports []importRef) []string {
+	if len(imports) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(imports))
+	for _, imp := range imports {
+		paths = append(paths, imp.Path)
+	}
+	return paths
+}
+
+// getImports gets imports and their positions from a Go file using afero.Fs,
+// together with the suppression comments attached to them
+func (g *Goverhaul) getImports(path string) ([]importRef, []Suppression, error) {
+	file, err := g.parseFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file.imports, file.suppressions, nil
+}
+
+// parsedFile is what the linter reads from the header of a Go file
+type parsedFile struct {
+	pkg          string // The package name
+	imports      []importRef
+	suppressions []Suppression
+	constraint   constraint.Expr // The //go:build constraint, nil when there is none
+	generated    bool            // Whether the file has a "// Code generated ... DO NOT EDIT." comment
+}
+
+// parseFile parses the package clause, the imports and the comments of a Go file
+func (g *Goverhaul) parseFile(path string) (parsedFile, error) {
 	fset := token.NewFileSet()
 
 	// Read the file content using afero.Fs
 	content, err := afero.ReadFile(g.fs, path)
 	if err != nil {
-		return nil, WithDetails(WithFile(NewFSError("failed to read Go file", err), path),
+		return parsedFile{}, WithDetails(WithFile(NewFSError("failed to read Go file", err), path),
 			"Make sure the file exists and is readable")
 	}
 
 	// Parse the file content
-	file, err := parser.ParseFile(fset, path, content, parser.ImportsOnly)
+	file, err := parser.ParseFile(fset, path, content, parser.ImportsOnly|parser.ParseComments)
 	if err != nil {
-		return nil, WithDetails(WithFile(NewParseError("failed to parse Go file", err), path),
+		return parsedFile{}, WithDetails(WithFile(NewParseError("failed to parse Go file", err), path),
 			"Make sure the file is a valid Go source file")
 	}
 
-	var imports []string
+	parsed := parsedFile{
+		pkg:          file.Name.Name,
+		suppressions: fileSuppressions(fset, file),
+		constraint:   buildConstraint(file),
+		generated:    ast.IsGenerated(file),
+	}
 	for _, s := range file.Imports {
-		imports = append(imports, strings.Trim(s.Path.Value, `"`))
+		parsed.imports = append(parsed.imports, importRef{
+			Path: strings.Trim(s.Path.Value, `"`),
+			Pos:  fset.Position(s.Path.Pos()),
+			End:  fset.Position(s.Path.End()),
+		})
 	}
 
-	return imports, nil
+	return parsed, nil
 }
 
-// RuleMatcher encapsulates the logic for matching imports against rules
-type RuleMatcher struct {
-	rule          Rule
-	moduleName    string
-	allowedSet    map[string]bool
-	prohibitedMap map[string]string
-}
-
-// newRuleMatcherWithFs creates a new RuleMatcher using a custom Fs
-func newRuleMatcherWithFs(rule Rule, moduleNameOrPath string, fs afero.Fs) *RuleMatcher {
-	// Extract module name if moduleNameOrPath is a path to go.mod
-	moduleName := moduleNameOrPath
-
-	// First check if the file exists at the given path
-	if strings.HasSuffix(moduleNameOrPath, ".mod") {
-		fileInfo, err := fs.Stat(moduleNameOrPath)
-		if err == nil && !fileInfo.IsDir() {
-			extractedName, err := getModuleName(fs, moduleNameOrPath)
-			if err == nil {
-				moduleName = extractedName
-			}
-		} else {
-			// Try to find go.mod in the project root
-			rootModPath := "go.mod"
-			extractedName, err := getModuleName(fs, rootModPath)
-			if err == nil {
-				moduleName = extractedName
-			}
+// compiledRule is a Rule whose path and import lists have been compiled into patterns.
+// It is immutable once compiled and shared by all the files of a run.
+type compiledRule struct {
+	rule       Rule
+	dir        string       // The normalized rule path, when it is a plain directory
+	path       *pathPattern // nil when the rule path is a plain directory
+	allowed    patternList
+	prohibited patternList   // Entries are parallel to rule.Prohibited
+	tests      *TestsPolicy  // The tests policy of the rule, nil to check test files like the others
+	testsRule  *compiledRule // The rule checking test files in separate mode
+	transitive bool          // Whether the rule checks the packages reached through other packages
+}
+
+// compileRule validates the severities of a rule and compiles its patterns
+func compileRule(rule Rule) (*compiledRule, error) {
+	// Normalize a copy, the prohibited entries are shared with the configuration
+	rules := []Rule{rule}
+	rules[0].Prohibited = slices.Clone(rule.Prohibited)
+	if err := normalizeRuleSeverities(rules); err != nil {
+		return nil, err
+	}
+	rule = rules[0]
+	compiled := &compiledRule{rule: rule}
+
+	if hasWildcard(rule.Path) {
+		p, err := compilePattern(rule.Path)
+		if err != nil {
+			return nil, err
 		}
+		compiled.path = &p
+	} else {
+		compiled.dir = NormalizePath(rule.Path)
+	}
+
+	switch rule.AllowedMode {
+	case "", MatchExact, MatchSubtree, MatchPattern:
+	default:
+		return nil, WithDetails(NewConfigError("invalid allowed_mode "+string(rule.AllowedMode), nil),
+			"allowed_mode must be one of: exact, subtree, pattern")
+	}
+
+	var err error
+	compiled.allowed, err = compilePatternList(rule.Allowed, rule.AllowedMode)
+	if err != nil {
+		return nil, err
 	}
 
-	matcher := &RuleMatcher{
-		rule:          rule,
-		moduleName:    moduleName,
-		allowedSet:    make(map[string]bool),
-		prohibitedMap: make(map[string]string),
+	names := make([]string, 0, len(rule.Prohibited))
+	for _, prohibited := range rule.Prohibited {
+		names = append(names, prohibited.Name)
+	}
+	compiled.prohibited, err = compilePatternList(names, MatchExact)
+	if err != nil {
+		return nil, err
 	}
 
-	// Prepare allowed set
-	for _, allowed := range rule.Allowed {
-		// Add the original path to the allowed set
-		matcher.allowedSet[allowed] = true
+	compiled.transitive = rule.AllowedTransitive && len(rule.Allowed) > 0 ||
+		slices.ContainsFunc(rule.Prohibited, func(p ProhibitedPkg) bool { return p.Transitive })
+	return compiled, nil
+}
+
+// compileTests sets the tests policy of the rule, compiling its lists in separate mode
+func (c *compiledRule) compileTests(policy *TestsPolicy) error {
+	if err := validateTestsPolicy(policy); err != nil {
+		return err
+	}
+	if policy == nil || policy.Mode == "" || policy.Mode == TestsInclude {
+		return nil
+	}
 
-		// Handle module-relative paths (only for simple paths without dots)
-		if !strings.Contains(allowed, ".") {
-			matcher.allowedSet[strings.Join([]string{moduleName, allowed}, "/")] = true
+	c.tests = policy
+	if policy.Mode == TestsSeparate {
+		tests, err := compileRule(testsRule(c.rule, policy))
+		if err != nil {
+			return WithDetails(err, "Tests policy")
 		}
+		c.testsRule = tests
 	}
+	return nil
+}
 
-	// Prepare prohibited map
-	for _, prohibited := range rule.Prohibited {
-		// Add the original path to the prohibited map
-		matcher.prohibitedMap[prohibited.Name] = prohibited.Cause
+// forFile returns the rule checking a file of the given test package ("" for non-test files):
+// the rule itself, its tests rule, or nil when the file is excluded by the tests policy
+func (c *compiledRule) forFile(testPackage TestPackages) *compiledRule {
+	if testPackage == "" || c.tests == nil || !c.tests.covers(testPackage) {
+		return c
+	}
+	if c.tests.Mode == TestsExclude {
+		return nil
+	}
+	return c.testsRule
+}
 
-		// Handle module-relative paths (only for simple paths without dots)
-		if !strings.Contains(prohibited.Name, ".") {
-			matcher.prohibitedMap[strings.Join([]string{moduleName, prohibited.Name}, "/")] = prohibited.Cause
+// inContexts returns the build contexts, among the given ones, the rule applies in
+func (c *compiledRule) inContexts(contexts []string) []string {
+	if len(c.rule.Contexts) == 0 {
+		return contexts
+	}
+	var applies []string
+	for _, context := range contexts {
+		if slices.Contains(c.rule.Contexts, context) {
+			applies = append(applies, context)
 		}
 	}
+	return applies
+}
+
+// appliesTo checks if the rule applies to a file at the given location
+func (c *compiledRule) appliesTo(location fileLocation) bool {
+	if c.path == nil {
+		return ruleAppliesToDir(c.dir, location)
+	}
+	if c.path.matchPrefix(location.dir) {
+		return true
+	}
+	return location.moduleDir != "" && !IsAbsPath(c.rule.Path) && c.path.matchPrefix(location.moduleDir)
+}
+
+// RuleMatcher encapsulates the logic for matching imports against rules
+type RuleMatcher struct {
+	rule       Rule
+	compiled   *compiledRule
+	moduleName string
+	workspace  []string // The module paths of the go.work workspace, if any
+}
 
-	return matcher
+// newRuleMatcher creates a RuleMatcher for a compiled rule within the given module
+func newRuleMatcher(compiled *compiledRule, moduleName string) *RuleMatcher {
+	return &RuleMatcher{
+		rule:       compiled.rule,
+		compiled:   compiled,
+		moduleName: moduleName,
+	}
 }
 
 // IsProhibited checks if an import is prohibited by the rule
 func (m *RuleMatcher) IsProhibited(imp string) (string, bool) {
-	// Direct lookup for exact match
-	if cause, exists := m.prohibitedMap[imp]; exists {
-		return cause, true
-	}
+	prohibited, ok := m.matchProhibited(importRef{Path: imp})
+	return prohibited.Cause, ok
+}
 
-	// Check if the import path contains any of the prohibited paths
-	for prohibitedPath, cause := range m.prohibitedMap {
-		// Skip module-prefixed paths to avoid duplicates
-		if strings.Contains(prohibitedPath, "/") && !strings.HasPrefix(prohibitedPath, m.moduleName) {
-			if strings.HasSuffix(imp, prohibitedPath) {
-				return cause, true
-			}
-		}
+// matchProhibited returns the prohibited entry matching the import, if any.
+// The last matching entry wins, so a later negated entry lifts an earlier prohibition.
+func (m *RuleMatcher) matchProhibited(imp importRef) (ProhibitedPkg, bool) {
+	index, ok := m.compiled.prohibited.matchGroup(imp.Path, groupModule(imp, m.moduleName), imp.Group, m.workspace)
+	if !ok {
+		return ProhibitedPkg{}, false
 	}
-
-	return "", false
+	return m.rule.Prohibited[index], true
 }
 
 // IsAllowed checks if an import is allowed by the rule
 func (m *RuleMatcher) IsAllowed(imp string) bool {
+	return m.isAllowed(importRef{Path: imp})
+}
+
+// isAllowed checks if an import, possibly resolved by package analysis, is allowed by the rule
+func (m *RuleMatcher) isAllowed(imp importRef) bool {
+	allowed := m.compiled.allowed
 	// If there are no allowed imports specified, all imports are allowed
-	if len(m.rule.Allowed) == 0 {
+	if allowed.isEmpty() {
 		return true
 	}
-	return m.allowedSet[imp]
+
+	index, ok := allowed.matchGroup(imp.Path, groupModule(imp, m.moduleName), imp.Group, m.workspace)
+	if index < 0 {
+		// An allow-list made of exclusions only allows everything else
+		return allowed.onlyNegations()
+	}
+	return ok
 }
 
 // createViolation creates a LintViolation with the given parameters
-func createViolation(file, imp, rule, cause, details string) *LintViolation {
+func createViolation(file, imp, rule, ruleID, cause, details string) *LintViolation {
 	return &LintViolation{
-		File:    file,
-		Import:  imp,
-		Rule:    rule,
-		Cause:   cause,
-		Details: details,
+		File:     file,
+		Import:   imp,
+		Rule:     rule,
+		RuleID:   ruleID,
+		Cause:    cause,
+		Details:  details,
+		Severity: SeverityError,
 	}
 }
 
 // logAndCreateViolation logs an error and creates a violation
-func (m *RuleMatcher) logAndCreateViolation(logger *slog.Logger, file, imp, message, cause, details string) *LintViolation {
+func (m *RuleMatcher) logAndCreateViolation(logger *slog.Logger, file, imp, ruleID, message, cause, details string) *LintViolation {
 	if cause != "" {
 		logger.Error(message,
 			"file", file,
@@ -381,52 +937,75 @@ func (m *RuleMatcher) logAndCreateViolation(logger *slog.Logger, file, imp, mess
 			"import", imp)
 	}
 
-	return createViolation(file, imp, m.rule.Path, cause, details)
+	return createViolation(file, imp, ruleName(m.rule), ruleID, cause, details)
 }
 
 // CheckImport checks a single import against the rule
 func (m *RuleMatcher) CheckImport(imp string, normalizedPath string, logger *slog.Logger) *LintViolation {
-	// First check if the import is prohibited
-	cause, isProhibited := m.IsProhibited(imp)
-	if isProhibited {
-		details := "This import is explicitly prohibited"
-		if cause != "" {
-			details += " with cause: " + cause
-		}
+	return m.checkImport(importRef{Path: imp}, normalizedPath, logger)
+}
 
-		return m.logAndCreateViolation(logger, normalizedPath, imp, "Import is prohibited", cause, details)
+// checkImport checks a single import, possibly resolved by package analysis, against the rule
+func (m *RuleMatcher) checkImport(ref importRef, normalizedPath string, logger *slog.Logger) *LintViolation {
+	// First check if the import is prohibited
+	if prohibited, isProhibited := m.matchProhibited(ref); isProhibited {
+		return m.prohibitedViolation(logger, normalizedPath, ref.Path, prohibited)
 	}
 
 	// Then check if the import is allowed
-	if !m.IsAllowed(imp) {
-		details := "This import is not in the allowed list for this package"
-		return m.logAndCreateViolation(logger, normalizedPath, imp, "Import is not allowed", "", details)
+	if !m.isAllowed(ref) {
+		return m.allowedViolation(logger, normalizedPath, ref.Path)
 	}
 
 	return nil
 }
 
-// checkImports checks all imports in a file against a rule using the provided file system
-func (g *Goverhaul) checkImports(path string, imports []string, rule Rule, moduleName string) []LintViolation {
-	violations := make([]LintViolation, 0)
+// prohibitedViolation creates the violation of an import matching a prohibited entry
+func (m *RuleMatcher) prohibitedViolation(logger *slog.Logger, normalizedPath, imp string, prohibited ProhibitedPkg) *LintViolation {
+	details := "This import is explicitly prohibited"
+	if prohibited.Cause != "" {
+		details += " with cause: " + prohibited.Cause
+	}
+
+	v := m.logAndCreateViolation(logger, normalizedPath, imp, prohibitedRuleID(m.rule, prohibited),
+		"Import is prohibited", prohibited.Cause, details)
+	v.Severity = prohibitedSeverity(m.rule, prohibited)
+	v.Description = cmp.Or(prohibited.Description, m.rule.Description)
+	return v
+}
 
-	g.logger.Debug("Checking imports", "path", path, "rule_path", rule.Path, "modfile_path", moduleName)
+// allowedViolation creates the violation of an import missing from the allow-list
+func (m *RuleMatcher) allowedViolation(logger *slog.Logger, normalizedPath, imp string) *LintViolation {
+	details := "This import is not in the allowed list for this package"
+	v := m.logAndCreateViolation(logger, normalizedPath, imp, allowedRuleID(m.rule),
+		"Import is not allowed", "", details)
+	v.Severity = allowedSeverity(m.rule)
+	v.Description = m.rule.Description
+	return v
+}
 
-	// Create a rule matcher with the provided file system
-	matcher := newRuleMatcherWithFs(rule, moduleName, g.fs)
+// checkImports checks all imports in a file against a rule, within the module the file belongs to
+func (g *Goverhaul) checkImports(path string, imports []importRef, compiled *compiledRule, moduleName string) []LintViolation {
+	violations := make([]LintViolation, 0)
+	rule := compiled.rule
 
-	g.logger.Debug("Rule matcher created", "path", path, "rule_path", rule.Path, "module_name", matcher.moduleName)
-	g.logger.Debug("Prohibited imports", "path", path, "prohibited", matcher.prohibitedMap)
+	g.logger.Debug("Checking imports", "path", path, "rule_path", rule.Path, "module_name", moduleName)
+	matcher := newRuleMatcher(compiled, moduleName)
+	if g.modules != nil {
+		matcher.workspace = g.modules.workspacePaths()
+	}
 
 	// Normalize the path for consistent reporting
 	normalizedPath := NormalizePath(path)
 
 	// Check each import
 	for _, imp := range imports {
-		g.logger.Debug("Checking import", "path", path, "import", imp)
-		violation := matcher.CheckImport(imp, normalizedPath, g.logger)
+		g.logger.Debug("Checking import", "path", path, "import", imp.Path)
+		violation := matcher.checkImport(imp, normalizedPath, g.logger)
 		if violation != nil {
-			g.logger.Debug("Violation found", "path", path, "import", imp, "rule", rule.Path)
+			g.logger.Debug("Violation found", "path", path, "import", imp.Path, "rule", rule.Path, "line", imp.Pos.Line)
+			violation.Line, violation.Column = imp.Pos.Line, imp.Pos.Column
+			violation.EndLine, violation.EndColumn = imp.End.Line, imp.End.Column
 			violations = append(violations, *violation)
 		}
 	}
@@ -2,10 +2,13 @@ package goverhaul
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -73,6 +76,42 @@ func TestNewLinter(t *testing.T) {
 	}
 }
 
+func TestNewLinterInvalidPattern(t *testing.T) {
+	cfg := Config{
+		Rules: []Rule{
+			{
+				Path:    "internal",
+				Allowed: []string{"internal/[a-"},
+			},
+		},
+	}
+
+	linter, err := NewLinter(cfg, nil, afero.NewMemMapFs())
+	assert.ErrorIs(t, err, ErrConfig)
+	assert.Nil(t, linter)
+}
+
+func TestNewLinterInvalidAllowedMode(t *testing.T) {
+	cfg := Config{
+		Rules: []Rule{
+			{
+				Path:        "internal",
+				Allowed:     []string{"fmt"},
+				AllowedMode: "fuzzy",
+			},
+		},
+	}
+
+	_, err := NewLinter(cfg, nil, afero.NewMemMapFs())
+	assert.ErrorIs(t, err, ErrConfig)
+}
+
+func TestNewLinterInvalidFailOn(t *testing.T) {
+	linter, err := NewLinter(Config{FailOn: "warnings"}, nil, afero.NewMemMapFs())
+	assert.ErrorIs(t, err, ErrConfig)
+	assert.Nil(t, linter)
+}
+
 func TestEnsureLogger(t *testing.T) {
 	t.Run("should return provided logger", func(t *testing.T) {
 		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
@@ -222,7 +261,8 @@ func Connect() {
 
 			violations, err := linter.Lint(test.path)
 			if name == "should detect violations" {
-				assert.NoError(t, err)
+				assert.ErrorIs(t, err, ErrLint)
+				assert.Contains(t, err.Error(), test.errorContains)
 				assert.NotNil(t, violations)
 				assert.Greater(t, len(violations.Violations), 0)
 			} else {
@@ -233,6 +273,127 @@ func Connect() {
 	}
 }
 
+func TestLintFailOn(t *testing.T) {
+	setupFs := func(fs afero.Fs) error {
+		err := afero.WriteFile(fs, "go.mod", []byte("module example.com\n\ngo 1.20\n"), 0o644)
+		if err != nil {
+			return err
+		}
+		return afero.WriteFile(fs, "internal/db.go", []byte(`package db
+
+import "unsafe"
+`), 0o644)
+	}
+
+	tests := map[string]struct {
+		failOn      Severity
+		expectError bool
+	}{
+		"should fail on error by default": {
+			failOn:      "",
+			expectError: true,
+		},
+		"should fail when threshold is below error": {
+			failOn:      SeverityWarning,
+			expectError: true,
+		},
+		"should not fail when threshold is none": {
+			failOn:      SeverityNone,
+			expectError: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			memFs := afero.NewMemMapFs()
+			require.NoError(t, setupFs(memFs), "Failed to setup filesystem")
+
+			cfg := Config{
+				Modfile: "go.mod",
+				FailOn:  test.failOn,
+				Rules: []Rule{
+					{
+						Path:       "internal",
+						Prohibited: []ProhibitedPkg{{Name: "unsafe"}},
+					},
+				},
+			}
+			linter, err := NewLinter(cfg, nil, memFs)
+			require.NoError(t, err, "Failed to create linter")
+
+			violations, err := linter.Lint("internal")
+			require.NotNil(t, violations)
+			assert.Len(t, violations.Violations, 1)
+			if test.expectError {
+				assert.ErrorIs(t, err, ErrLint)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLintUnparsableFile(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com\n\ngo 1.20\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "internal/db.go", []byte("package db\n\nimport \"unsafe\"\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "internal/broken.go", []byte("package db\n\nimport (\n"), 0o644))
+
+	cfg := Config{
+		Modfile: "go.mod",
+		FailOn:  SeverityNone,
+		Rules:   []Rule{{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}}},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	assert.ErrorIs(t, err, ErrParse)
+	assert.NotErrorIs(t, err, ErrLint)
+	require.NotNil(t, violations)
+	assert.Len(t, violations.Violations, 1, "the other files are still checked")
+	assert.Equal(t, []string{"internal/broken.go"}, linter.Summary().UnparsedFiles)
+}
+
+func TestLintSummary(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com\n\ngo 1.20\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "pkg/a.go", []byte("package pkg\n\nimport \"os\"\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "pkg/b.go", []byte("package pkg\n\nimport \"fmt\"\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "pkg/b_test.go", []byte("package pkg\n\nimport \"testing\"\n"), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Tests:       &TestsPolicy{Mode: TestsSeparate, Allowed: []string{"@stdlib"}},
+		Rules: []Rule{
+			{Path: "pkg", Allowed: []string{"fmt"}},
+			{Path: "cmd", Allowed: []string{"fmt"}},
+		},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err, "Failed to create linter")
+
+	_, err = linter.Lint("pkg")
+	require.ErrorIs(t, err, ErrLint)
+
+	summary := linter.Summary()
+	assert.Equal(t, 3, summary.FilesScanned)
+	assert.Equal(t, 0, summary.FilesCached)
+	// The pkg rule and its tests rule, the cmd rule applies to none of the files
+	assert.Equal(t, 2, summary.RulesEvaluated)
+	assert.NotEmpty(t, summary.Version)
+	assert.Greater(t, summary.Duration, time.Duration(0))
+
+	// Files served from the cache count the rules they were checked against
+	_, err = linter.Lint("pkg")
+	require.ErrorIs(t, err, ErrLint)
+	summary = linter.Summary()
+	assert.Equal(t, 3, summary.FilesCached)
+	assert.Equal(t, 2, summary.RulesEvaluated)
+}
+
 func TestWalkAndLintSuccess(t *testing.T) {
 	tests := map[string]struct {
 		setupFs            func(fs afero.Fs) error
@@ -548,10 +709,8 @@ func main() {
 			linter, err := NewLinter(test.config, nil, memFs)
 			require.NoError(t, err, "Failed to create linter")
 
-			violations := NewLintViolations()
-			err = linter.lintFile(test.filePath, violations)
-			assert.NoError(t, err)
-			assert.Equal(t, test.expectedViolations, len(violations.Violations))
+			result := linter.lintFile(test.filePath)
+			assert.Equal(t, test.expectedViolations, len(result.violations))
 		})
 	}
 }
@@ -622,13 +781,47 @@ func main() {
 			linter, err := NewLinter(Config{}, nil, memFs)
 			require.NoError(t, err, "Failed to create linter")
 
-			imports, err := linter.getImports(test.filePath)
+			imports, _, err := linter.getImports(test.filePath)
 			assert.NoError(t, err)
-			assert.Equal(t, test.expectedImports, imports)
+			assert.Equal(t, test.expectedImports, importPaths(imports))
 		})
 	}
 }
 
+func TestViolationPositions(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com\n\ngo 1.20\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "internal/db.go", []byte(`package db
+
+import (
+	"fmt"
+	u "unsafe"
+)
+`), 0o644))
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules: []Rule{
+			{
+				Path:       "internal",
+				Prohibited: []ProhibitedPkg{{Name: "unsafe"}},
+			},
+		},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err, "Failed to create linter")
+
+	result := linter.lintFile("internal/db.go")
+	require.Len(t, result.violations, 1)
+
+	v := result.violations[0]
+	assert.Equal(t, 5, v.Line)
+	assert.Equal(t, 4, v.Column)
+	assert.Equal(t, 5, v.EndLine)
+	assert.Equal(t, 12, v.EndColumn)
+	assert.Equal(t, "internal/db.go:5:4", v.Location())
+}
+
 func TestGetImportsFailure(t *testing.T) {
 	tests := map[string]struct {
 		setupFs       func(fs afero.Fs) error
@@ -670,7 +863,7 @@ func main() {
 			linter, err := NewLinter(Config{}, nil, memFs)
 			require.NoError(t, err, "Failed to create linter")
 
-			imports, err := linter.getImports(test.filePath)
+			imports, _, err := linter.getImports(test.filePath)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), test.errorContains)
 			assert.Nil(t, imports)
@@ -705,6 +898,13 @@ func TestRuleAppliesToPath(t *testing.T) {
 			path:     "pkg/file.go",
 			expected: false,
 		},
+		"should not match directory sharing a suffix": {
+			rule: Rule{
+				Path: "internal",
+			},
+			path:     "pkg/myinternal/a.go",
+			expected: false,
+		},
 		"should handle absolute paths": {
 			rule: Rule{
 				Path: "/project/internal",
@@ -712,16 +912,79 @@ func TestRuleAppliesToPath(t *testing.T) {
 			path:     "/project/internal/file.go",
 			expected: true,
 		},
+		"should match single segment wildcard": {
+			rule: Rule{
+				Path: "internal/*/domain",
+			},
+			path:     "internal/user/domain/entity/user.go",
+			expected: true,
+		},
+		"should match multi segment wildcard": {
+			rule: Rule{
+				Path: "services/**/adapters",
+			},
+			path:     "services/user/http/adapters/handler.go",
+			expected: true,
+		},
+		"should not match wildcard with missing segment": {
+			rule: Rule{
+				Path: "internal/*/domain",
+			},
+			path:     "internal/domain/user.go",
+			expected: false,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			result := ruleAppliesToPath(test.rule, test.path)
-			assert.Equal(t, test.expected, result)
+			compiled, err := compileRule(test.rule)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, compiled.appliesTo(locateFile(test.path, goModule{})))
 		})
 	}
 }
 
+func TestLintAbsoluteAndNestedPaths(t *testing.T) {
+	dir := t.TempDir()
+	osFs := afero.NewOsFs()
+	files := map[string]string{
+		"go.mod":                         "module example.com\n\ngo 1.20\n",
+		"internal/user/adapters/db.go":   "package adapters\n\nimport \"unsafe\"\n",
+		"internal/user/domain/domain.go": "package domain\n\nimport \"unsafe\"\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, osFs.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, afero.WriteFile(osFs, path, []byte(content), 0o644))
+	}
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules: []Rule{
+			{ID: "adapters", Path: "internal/*/adapters", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}},
+			{ID: "domain", Path: "internal/user/domain", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}},
+		},
+	}
+	lint := func(path string) []string {
+		linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), osFs)
+		require.NoError(t, err)
+		violations, err := linter.Lint(path)
+		require.ErrorIs(t, err, ErrLint)
+		var ids []string
+		for _, v := range violations.Violations {
+			ids = append(ids, v.Rule)
+		}
+		return ids
+	}
+
+	// Rule paths are relative to the module root, wherever the linted path is
+	t.Chdir(t.TempDir())
+	assert.ElementsMatch(t, []string{"adapters", "domain"}, lint(dir))
+
+	t.Chdir(filepath.Join(dir, "internal"))
+	assert.ElementsMatch(t, []string{"adapters", "domain"}, lint("."))
+}
+
 func TestRuleMatcher(t *testing.T) {
 	tests := map[string]struct {
 		rule           Rule
@@ -774,12 +1037,102 @@ func TestRuleMatcher(t *testing.T) {
 				"os":                           false,
 			},
 		},
+		"should not match prohibited paths of other modules by suffix": {
+			rule: Rule{
+				Path: "internal",
+				Prohibited: []ProhibitedPkg{
+					{Name: "internal/db"},
+				},
+			},
+			imports: []string{"example.com/s1/lib/internal/db", "example.com/foo/internal/db"},
+			expectedResult: map[string]bool{
+				"example.com/s1/lib/internal/db": true,
+				"example.com/foo/internal/db":    false,
+			},
+		},
+		"should match prohibited patterns with negation": {
+			rule: Rule{
+				Path: "internal",
+				Prohibited: []ProhibitedPkg{
+					{Name: "github.com/aws/aws-sdk-go-v2/..."},
+					{Name: "internal/**"},
+					{Name: "!internal/shared"},
+				},
+			},
+			imports: []string{
+				"github.com/aws/aws-sdk-go-v2/service/s3",
+				"example.com/s1/lib/internal/db",
+				"example.com/s1/lib/internal/shared",
+			},
+			expectedResult: map[string]bool{
+				"github.com/aws/aws-sdk-go-v2/service/s3": true,
+				"example.com/s1/lib/internal/db":          true,
+				"example.com/s1/lib/internal/shared":      false,
+			},
+		},
+		"should match allowed patterns": {
+			rule: Rule{
+				Path:    "internal/domain",
+				Allowed: []string{"fmt", "internal/*/domain"},
+			},
+			imports: []string{"fmt", "example.com/s1/lib/internal/user/domain", "os"},
+			expectedResult: map[string]bool{
+				"fmt": false,
+				"example.com/s1/lib/internal/user/domain": false,
+				"os": true,
+			},
+		},
+		"should allow subpackages in subtree mode": {
+			rule: Rule{
+				Path:        "internal/domain",
+				Allowed:     []string{"encoding", "internal/domain", "exact:errors"},
+				AllowedMode: MatchSubtree,
+			},
+			imports: []string{"encoding/json", "example.com/s1/lib/internal/domain/user", "errors", "errors/internal"},
+			expectedResult: map[string]bool{
+				"encoding/json": false,
+				"example.com/s1/lib/internal/domain/user": false,
+				"errors":          false,
+				"errors/internal": true,
+			},
+		},
+		"should match import group selectors": {
+			rule: Rule{
+				Path:    "internal/domain",
+				Allowed: []string{"@stdlib", "@module"},
+				Prohibited: []ProhibitedPkg{
+					{Name: "@thirdparty", Cause: "the domain has no external dependencies"},
+				},
+			},
+			imports: []string{"context", "example.com/s1/lib/internal/domain/user", "github.com/lib/pq"},
+			expectedResult: map[string]bool{
+				"context": false,
+				"example.com/s1/lib/internal/domain/user": false,
+				"github.com/lib/pq":                       true,
+			},
+		},
+		"should allow everything but negated entries": {
+			rule: Rule{
+				Path:    "internal/domain",
+				Allowed: []string{"!unsafe"},
+			},
+			imports: []string{"fmt", "unsafe"},
+			expectedResult: map[string]bool{
+				"fmt":    false,
+				"unsafe": true,
+			},
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			memFs := afero.NewMemMapFs()
-			matcher := newRuleMatcherWithFs(test.rule, "example.com/s1/lib", memFs)
+			require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com/s1/lib\n\ngo 1.20\n"), 0o644))
+			module := newModuleIndex(memFs, "go.mod").moduleOf(".")
+
+			compiled, err := compileRule(test.rule)
+			require.NoError(t, err)
+			matcher := newRuleMatcher(compiled, module.path)
 
 			for _, imp := range test.imports {
 				violation := matcher.CheckImport(imp, "test.go", slog.Default())
@@ -830,3 +1183,288 @@ func (m *mockErrorFs) MkdirAll(path string, perm os.FileMode) error {
 	}
 	return m.Fs.MkdirAll(path, perm)
 }
+
+func TestLintIncrementalMultipleRules(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go",
+		[]byte("package api\n\nimport (\n\t\"os\"\n\t\"unsafe\"\n)\n"), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules: []Rule{
+			{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}},
+			{Path: "internal/api", Prohibited: []ProhibitedPkg{{Name: "os"}}},
+		},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	first, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, first.Violations, 2)
+
+	// The cache entry of the file holds the violations of every rule
+	second, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	assert.Len(t, second.Violations, 2)
+	assert.Equal(t, 1, linter.Summary().FilesCached)
+}
+
+func TestLintIncrementalCacheStatus(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go",
+		[]byte("package api\n\nimport \"unsafe\"\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/clean.go",
+		[]byte("package api\n\nimport \"fmt\"\n"), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules:       []Rule{{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}}},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	first, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, first.Violations, 1)
+	assert.False(t, first.Violations[0].Cached)
+	summary := linter.Summary()
+	assert.Equal(t, [3]int{0, 0, 2}, [3]int{summary.CacheHitsClean, summary.CacheHitsViolations, summary.CacheMisses})
+
+	second, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, second.Violations, 1)
+	assert.True(t, second.Violations[0].Cached)
+	summary = linter.Summary()
+	assert.Equal(t, [3]int{1, 1, 0}, [3]int{summary.CacheHitsClean, summary.CacheHitsViolations, summary.CacheMisses})
+	assert.Equal(t, 2, summary.FilesCached)
+
+	// Changing a file turns its entry into a miss
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/clean.go",
+		[]byte("package api\n\nimport \"os\"\n"), 0o644))
+	_, err = linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	summary = linter.Summary()
+	assert.Equal(t, [3]int{0, 1, 1}, [3]int{summary.CacheHitsClean, summary.CacheHitsViolations, summary.CacheMisses})
+}
+
+func TestLintIncrementalConfigChange(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go",
+		[]byte("package api\n\nimport \"unsafe\"\n"), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules:       []Rule{{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}}}},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+	_, err = linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+
+	// Editing the rule invalidates the cached violations of the file
+	cfg.Rules[0].Prohibited[0].Cause = "unsafe code needs a review"
+	linter, err = NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+	violations, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 1)
+	assert.Equal(t, "unsafe code needs a review", violations.Violations[0].Cause)
+	assert.Zero(t, linter.Summary().FilesCached)
+}
+
+func TestRuleIDsAndSeverities(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "internal/api/api.go",
+		[]byte("package api\n\nimport (\n\t\"os\"\n\t\"unsafe\"\n)\n"), 0o644))
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules: []Rule{
+			{
+				ID:          "api-no-unsafe",
+				Path:        "internal/api",
+				Description: "The API must be memory safe",
+				Prohibited:  []ProhibitedPkg{{Name: "unsafe"}},
+			},
+			{
+				ID:       "api-no-os",
+				Path:     "internal/api",
+				Severity: SeverityWarning,
+				Prohibited: []ProhibitedPkg{{
+					ID:          "api-os",
+					Name:        "os",
+					Severity:    SeverityInfo,
+					Description: "Read the configuration instead",
+				}},
+			},
+		},
+	}
+
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 2)
+
+	assert.Equal(t, "api-no-os", violations.Violations[0].Rule)
+	assert.Equal(t, "api-os", violations.Violations[0].RuleID)
+	assert.Equal(t, SeverityInfo, violations.Violations[0].Severity)
+	assert.Equal(t, "Read the configuration instead", violations.Violations[0].Description)
+
+	assert.Equal(t, "api-no-unsafe", violations.Violations[1].Rule)
+	assert.Equal(t, "api-no-unsafe:prohibited:unsafe", violations.Violations[1].RuleID)
+	assert.Equal(t, SeverityError, violations.Violations[1].Severity)
+	assert.Equal(t, "The API must be memory safe", violations.Violations[1].Description)
+
+	assert.Contains(t, violations.PrintByFile(), "  - [info] Line: 4, Rule: api-no-os")
+
+	// Without the error, the remaining violations do not fail the run
+	cfg.Rules = cfg.Rules[1:]
+	linter, err = NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+	violations, err = linter.Lint("internal")
+	require.NoError(t, err)
+	assert.Len(t, violations.Violations, 1)
+}
+
+func TestNewLinterInvalidRuleIDsAndSeverities(t *testing.T) {
+	tests := map[string][]Rule{
+		"duplicate rule id": {
+			{ID: "api", Path: "internal/api"},
+			{ID: "api", Path: "internal/web"},
+		},
+		"duplicate prohibited id": {
+			{Path: "internal/api", Prohibited: []ProhibitedPkg{{Name: "os", ID: "no-os"}}},
+			{Path: "internal/web", Prohibited: []ProhibitedPkg{{Name: "os", ID: "no-os"}}},
+		},
+		"prohibited id equal to a rule id": {
+			{ID: "api", Path: "internal/api", Prohibited: []ProhibitedPkg{{Name: "os", ID: "api"}}},
+		},
+		"prohibited id equal to an allow-list id": {
+			{ID: "api", Path: "internal/api", Allowed: []string{"fmt"}},
+			{Path: "internal/web", Prohibited: []ProhibitedPkg{{Name: "os", ID: "api:allowed"}}},
+		},
+		"invalid rule severity": {
+			{Path: "internal/api", Severity: "fatal"},
+		},
+		"invalid prohibited severity": {
+			{Path: "internal/api", Prohibited: []ProhibitedPkg{{Name: "os", Severity: SeverityNone}}},
+		},
+	}
+
+	for name, rules := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewLinter(Config{Rules: rules}, nil, afero.NewMemMapFs())
+			assert.ErrorIs(t, err, ErrConfig)
+		})
+	}
+}
+
+// writeBenchmarkTree writes a module with the given number of Go files spread over packages,
+// each importing a few standard library and module packages
+func writeBenchmarkTree(tb testing.TB, fs afero.Fs, files int) {
+	tb.Helper()
+
+	require.NoError(tb, afero.WriteFile(fs, "go.mod", []byte("module example.com/bench\n\ngo 1.20\n"), 0o644))
+	for i := range files {
+		pkg := fmt.Sprintf("internal/pkg%d", i%50)
+		content := fmt.Sprintf(`package pkg%d
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"example.com/bench/internal/pkg%d"
+	"example.com/bench/internal/db"
+)
+
+func F%d() string { return fmt.Sprint(strings.ToUpper("x"), unsafe.Sizeof(0)) }
+`, i%50, (i+1)%50, i)
+		require.NoError(tb, afero.WriteFile(fs, fmt.Sprintf("%s/file%d.go", pkg, i), []byte(content), 0o644))
+	}
+}
+
+// benchmarkConfig returns rules checking every file of the benchmark tree
+func benchmarkConfig(jobs int) Config {
+	return Config{
+		Modfile: "go.mod",
+		Jobs:    jobs,
+		Rules: []Rule{
+			{Path: "internal", Prohibited: []ProhibitedPkg{{Name: "unsafe"}, {Name: "internal/db"}}},
+			{Path: "internal", Allowed: []string{"@stdlib", "internal/..."}},
+		},
+	}
+}
+
+func TestLintParallelDeterministic(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	writeBenchmarkTree(t, memFs, 200)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	serial, err := NewLinter(benchmarkConfig(1), logger, memFs)
+	require.NoError(t, err)
+	expected, err := serial.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, expected.Violations, 400)
+
+	parallel, err := NewLinter(benchmarkConfig(8), logger, memFs)
+	require.NoError(t, err)
+	for range 3 {
+		violations, err := parallel.Lint("internal")
+		require.ErrorIs(t, err, ErrLint)
+		assert.Equal(t, expected.Violations, violations.Violations)
+		assert.Equal(t, 200, parallel.Summary().FilesScanned)
+	}
+}
+
+func BenchmarkLint(b *testing.B) {
+	memFs := afero.NewMemMapFs()
+	writeBenchmarkTree(b, memFs, 2000)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, jobs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			linter, err := NewLinter(benchmarkConfig(jobs), logger, memFs)
+			require.NoError(b, err)
+
+			for b.Loop() {
+				if _, err := linter.Lint("internal"); !errors.Is(err, ErrLint) {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLintManyRules(b *testing.B) {
+	memFs := afero.NewMemMapFs()
+	writeBenchmarkTree(b, memFs, 5000)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cfg := benchmarkConfig(1)
+	for i := range 50 {
+		cfg.Rules = append(cfg.Rules, Rule{
+			Path:       fmt.Sprintf("internal/pkg%d", i),
+			Allowed:    []string{"@stdlib", "internal/db", fmt.Sprintf("internal/pkg%d", (i+1)%50)},
+			Prohibited: []ProhibitedPkg{{Name: "github.com/..."}, {Name: fmt.Sprintf("internal/pkg%d", (i+2)%50)}},
+		})
+	}
+
+	linter, err := NewLinter(cfg, logger, memFs)
+	require.NoError(b, err)
+
+	for b.Loop() {
+		if _, err := linter.Lint("internal"); !errors.Is(err, ErrLint) {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package goverhaul
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+	"golang.org/x/mod/modfile"
+)
+
+// goModule is a Go module of the linted tree
+type goModule struct {
+	root string // The directory holding the module's go.mod
+	path string // The module path declared in go.mod
+}
+
+// importDir returns the directory of an import within the module, relative to the directory
+// the module root is relative to, and whether the import belongs to the module
+func (m goModule) importDir(imp string) (string, bool) {
+	if m.path == "" {
+		return "", false
+	}
+	if imp == m.path {
+		return m.root, true
+	}
+	rest, ok := strings.CutPrefix(imp, m.path+"/")
+	if !ok {
+		return "", false
+	}
+	return JoinPaths(m.root, rest), true
+}
+
+// moduleIndex finds the module of each linted directory, walking up to the nearest go.mod
+// like the go command does, and memoizes the result so that each go.mod is read once per run.
+// It also holds the modules of the go.work workspace the linted tree belongs to, if any.
+// It is safe for concurrent use.
+type moduleIndex struct {
+	fs        afero.Fs
+	modfile   string // The name of the go.mod files, from Config.Modfile
+	mu        sync.RWMutex
+	dirs      map[string]goModule // Directory -> module, the zero module when there is none
+	workspace []goModule          // The modules used by go.work, in declaration order
+}
+
+// newModuleIndex creates an empty module index looking for go.mod files with the given name
+func newModuleIndex(fs afero.Fs, modfileName string) *moduleIndex {
+	if modfileName == "" {
+		modfileName = "go.mod"
+	}
+	return &moduleIndex{
+		fs:      fs,
+		modfile: modfileName,
+		dirs:    make(map[string]goModule),
+	}
+}
+
+// moduleOf returns the module the given directory belongs to,
+// or the zero module when no go.mod is found in the directory or its parents
+func (i *moduleIndex) moduleOf(dir string) goModule {
+	dir = NormalizePath(dir)
+
+	i.mu.RLock()
+	module, ok := i.dirs[dir]
+	i.mu.RUnlock()
+	if ok {
+		return module
+	}
+
+	modfilePath := JoinPaths(dir, i.modfile)
+	if info, err := i.fs.Stat(modfilePath); err == nil && !info.IsDir() {
+		if path, err := getModuleName(i.fs, modfilePath); err == nil {
+			module = goModule{root: dir, path: path}
+		}
+	} else if parent, ok := parentDir(dir); ok {
+		module = i.moduleOf(parent)
+	}
+
+	i.mu.Lock()
+	i.dirs[dir] = module
+	i.mu.Unlock()
+	return module
+}
+
+// workspacePaths returns the module paths of the go.work workspace
+func (i *moduleIndex) workspacePaths() []string {
+	paths := make([]string, 0, len(i.workspace))
+	for _, module := range i.workspace {
+		paths = append(paths, module.path)
+	}
+	return paths
+}
+
+// loadWorkspace reads the go.work file governing the given path, if any. As with the go
+// command, the nearest go.work in the path or its parents is used, unless GOWORK=off.
+func (i *moduleIndex) loadWorkspace(path string) error {
+	i.workspace = nil
+	if os.Getenv("GOWORK") == "off" {
+		return nil
+	}
+
+	dir := NormalizePath(path)
+	if info, err := i.fs.Stat(dir); err == nil && !info.IsDir() {
+		dir = DirPath(dir)
+	}
+	for {
+		workPath := JoinPaths(dir, "go.work")
+		if info, err := i.fs.Stat(workPath); err == nil && !info.IsDir() {
+			return i.readWorkspace(workPath)
+		}
+		parent, ok := parentDir(dir)
+		if !ok {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// readWorkspace reads the modules used by a go.work file
+func (i *moduleIndex) readWorkspace(workPath string) error {
+	content, err := afero.ReadFile(i.fs, workPath)
+	if err != nil {
+		return WithFile(NewFSError("failed to read go.work file", err), workPath)
+	}
+	work, err := modfile.ParseWork(workPath, content, nil)
+	if err != nil {
+		return WithFile(NewParseError("failed to parse go.work file", err), workPath)
+	}
+
+	workDir := DirPath(workPath)
+	for _, use := range work.Use {
+		root := use.Path
+		if !IsAbsPath(root) {
+			root = JoinPaths(workDir, root)
+		}
+		path, err := getModuleName(i.fs, JoinPaths(root, i.modfile))
+		if err != nil {
+			return WithDetails(err, "go.work uses "+use.Path)
+		}
+		i.workspace = append(i.workspace, goModule{root: NormalizePath(root), path: path})
+	}
+	return nil
+}
+
+// parentDir returns the parent of a directory. The parent of "." is the parent of the
+// working directory, so that go.mod files above the linted tree are found.
+func parentDir(dir string) (string, bool) {
+	if dir == "." || dir == "" {
+		dir = AbsPath(".")
+	}
+	parent := DirPath(dir)
+	if parent == dir {
+		return "", false
+	}
+	return parent, true
+}
@@ -0,0 +1,165 @@
+package goverhaul
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleIndex(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com/root\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "tools/go.mod", []byte("module example.com/tools\n"), 0o644))
+
+	index := newModuleIndex(memFs, "go.mod")
+	root := goModule{root: ".", path: "example.com/root"}
+	tools := goModule{root: "tools", path: "example.com/tools"}
+
+	assert.Equal(t, root, index.moduleOf("."))
+	assert.Equal(t, tools, index.moduleOf("tools"))
+	// Directories without a go.mod belong to the nearest module above them
+	assert.Equal(t, root, index.moduleOf("internal/db"))
+	assert.Equal(t, tools, index.moduleOf("tools/internal/gen"))
+
+	// go.mod files are read once per index
+	require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com/renamed\n"), 0o644))
+	assert.Equal(t, root, index.moduleOf("."))
+	assert.Equal(t, "example.com/renamed", newModuleIndex(memFs, "go.mod").moduleOf(".").path)
+}
+
+func TestModuleIndexWithoutModule(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, memFs.MkdirAll("internal/db", 0o755))
+
+	index := newModuleIndex(memFs, "go.mod")
+	assert.Equal(t, goModule{}, index.moduleOf("internal/db"))
+}
+
+func TestGoModuleImportDir(t *testing.T) {
+	module := goModule{root: "services/billing", path: "example.com/billing"}
+
+	tests := map[string]struct {
+		imp      string
+		expected string
+		ok       bool
+	}{
+		"module root":      {imp: "example.com/billing", expected: "services/billing", ok: true},
+		"package":          {imp: "example.com/billing/internal/db", expected: "services/billing/internal/db", ok: true},
+		"other module":     {imp: "example.com/billingx/db", ok: false},
+		"standard library": {imp: "fmt", ok: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, ok := module.importDir(test.imp)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, dir)
+		})
+	}
+}
+
+func TestLoadWorkspace(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "go.work", []byte("go 1.24\n\nuse (\n\t./api\n\t./billing\n)\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "api/go.mod", []byte("module example.com/api\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "billing/go.mod", []byte("module example.com/billing\n"), 0o644))
+
+	t.Run("should read the modules used by go.work", func(t *testing.T) {
+		index := newModuleIndex(memFs, "go.mod")
+		require.NoError(t, index.loadWorkspace("api"))
+		assert.Equal(t, []goModule{
+			{root: "api", path: "example.com/api"},
+			{root: "billing", path: "example.com/billing"},
+		}, index.workspace)
+		assert.Equal(t, []string{"example.com/api", "example.com/billing"}, index.workspacePaths())
+	})
+
+	t.Run("should ignore go.work when GOWORK=off", func(t *testing.T) {
+		t.Setenv("GOWORK", "off")
+		index := newModuleIndex(memFs, "go.mod")
+		require.NoError(t, index.loadWorkspace("."))
+		assert.Empty(t, index.workspace)
+	})
+
+	t.Run("should report a used module without go.mod", func(t *testing.T) {
+		brokenFs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(brokenFs, "go.work", []byte("go 1.24\n\nuse ./missing\n"), 0o644))
+		index := newModuleIndex(brokenFs, "go.mod")
+		assert.Error(t, index.loadWorkspace("."))
+	})
+}
+
+func TestLintNestedModules(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "services/go.work", []byte("go 1.24\n\nuse (\n\t./api\n\t./billing\n)\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/api/go.mod", []byte("module example.com/api\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/billing/go.mod", []byte("module example.com/billing\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/api/handlers/orders.go", []byte(`package handlers
+
+import (
+	"example.com/api/internal/db"
+	"example.com/billing/invoices"
+	"github.com/lib/pq"
+)
+`), 0o644))
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules: []Rule{
+			{
+				Path:       "services/api/handlers",
+				Allowed:    []string{"@module"},
+				Prohibited: []ProhibitedPkg{{Name: "internal/db", Cause: "Handlers go through services"}},
+			},
+		},
+	}
+	linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("services")
+	require.ErrorIs(t, err, ErrLint)
+
+	// internal/db expands to the prefix of the api module, the billing module of the
+	// workspace is part of @module, and the third party import is not allowed
+	var imports []string
+	for _, v := range violations.Violations {
+		imports = append(imports, v.Import)
+	}
+	assert.ElementsMatch(t, []string{"example.com/api/internal/db", "github.com/lib/pq"}, imports)
+}
+
+func TestLintIncrementalWorkspaceChange(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "services/go.work", []byte("go 1.24\n\nuse (\n\t./api\n\t./billing\n)\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/api/go.mod", []byte("module example.com/api\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/billing/go.mod", []byte("module example.com/billing\n"), 0o644))
+	require.NoError(t, afero.WriteFile(memFs, "services/api/handlers/orders.go", []byte(`package handlers
+
+import "example.com/billing/invoices"
+`), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules:       []Rule{{Path: "services/api/handlers", Allowed: []string{"@module"}}},
+	}
+	linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("services")
+	require.NoError(t, err)
+	assert.Empty(t, violations.Violations)
+
+	// Without billing in the workspace, its packages are not module imports anymore
+	require.NoError(t, afero.WriteFile(memFs, "services/go.work", []byte("go 1.24\n\nuse ./api\n"), 0o644))
+	violations, err = linter.Lint("services")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 1)
+	assert.Equal(t, "example.com/billing/invoices", violations.Violations[0].Import)
+	assert.Zero(t, linter.Summary().FilesCached)
+}
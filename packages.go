@@ -0,0 +1,181 @@
+package goverhaul
+
+import (
+	"cmp"
+	"os"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/tools/go/packages"
+)
+
+// AnalysisMode controls how the imports of a file are resolved
+type AnalysisMode string
+
+const (
+	// AnalysisSyntax reads the import paths as written in each file, and classifies them
+	// from the module path of the nearest go.mod
+	AnalysisSyntax AnalysisMode = "syntax"
+	// AnalysisPackages loads the packages of the linted tree with the go command, so that
+	// imports are resolved to their canonical package path and module, honoring vendor
+	// directories and replace directives
+	AnalysisPackages AnalysisMode = "packages"
+)
+
+// validateAnalysisMode checks the analysis mode, which needs the OS file system in packages mode.
+// The file system is not checked when fs is nil.
+func validateAnalysisMode(mode AnalysisMode, fs afero.Fs) error {
+	switch mode {
+	case "", AnalysisSyntax:
+		return nil
+	case AnalysisPackages:
+		if _, ok := fs.(*afero.OsFs); !ok && fs != nil {
+			return WithDetails(NewConfigError("packages analysis needs the OS file system", nil),
+				"The go command reads the packages from disk")
+		}
+		return nil
+	default:
+		return WithDetails(NewConfigError("invalid analysis mode "+string(mode), nil),
+			"analysis must be one of: syntax, packages")
+	}
+}
+
+// resolvedImport is an import resolved by package analysis
+type resolvedImport struct {
+	path  string // The canonical package path
+	group string // The selector of the import group: SelectorStdlib, SelectorModule or SelectorThirdParty
+}
+
+// resolvedFile is what package analysis knows about a Go file
+type resolvedFile struct {
+	module  string                    // The path of the module the file belongs to, empty when unknown
+	imports map[string]resolvedImport // Import path as written -> resolution
+}
+
+// packageIndex holds the files of the packages loaded by package analysis, keyed by absolute path.
+// It is read-only once loaded.
+type packageIndex struct {
+	files map[string]resolvedFile
+}
+
+// loadPackages loads the packages under dir, test packages included, with the go command.
+// Loading is offline: dependencies are read from the vendor directory or the module cache,
+// and imports of missing modules stay unresolved.
+func loadPackages(dir string) (*packageIndex, error) {
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule,
+		Dir:   dir,
+		Tests: true,
+		Env:   append(os.Environ(), "GOPROXY=off"),
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, WithDetails(WithFile(NewParseError("failed to load packages", err), dir),
+			"Packages analysis runs the go command offline: make sure the dependencies are vendored or in the module cache")
+	}
+
+	index := &packageIndex{files: make(map[string]resolvedFile)}
+	for _, pkg := range pkgs {
+		file := resolvedFile{imports: make(map[string]resolvedImport, len(pkg.Imports))}
+		if pkg.Module != nil {
+			file.module = pkg.Module.Path
+		}
+		for imp, imported := range pkg.Imports {
+			if resolved, ok := resolvePackage(imported); ok {
+				file.imports[imp] = resolved
+			}
+		}
+		for _, goFile := range pkg.GoFiles {
+			path := NormalizePath(goFile)
+			// Test variants list the files of the package again, with the same imports
+			if _, ok := index.files[path]; !ok {
+				index.files[path] = file
+			}
+		}
+	}
+	return index, nil
+}
+
+// resolvePackage returns the canonical path and the group of an imported package,
+// and false when the go command could not find it
+func resolvePackage(pkg *packages.Package) (resolvedImport, bool) {
+	resolved := resolvedImport{path: cmp.Or(pkg.PkgPath, pkg.ID)}
+	switch {
+	case pkg.Module != nil && pkg.Module.Main:
+		// The main modules are the module of the linted tree and the other modules of its workspace
+		resolved.group = SelectorModule
+	case pkg.Module != nil:
+		resolved.group = SelectorThirdParty
+	case len(pkg.Errors) > 0 || len(pkg.GoFiles) == 0:
+		return resolvedImport{}, false
+	default:
+		// Outside of GOPATH mode, only standard library packages have no module
+		resolved.group = SelectorStdlib
+	}
+	return resolved, true
+}
+
+// loadPackages loads the packages of the linted path in packages analysis
+func (g *Goverhaul) loadPackages(path string) error {
+	g.packages = nil
+	if g.cfg.Analysis != AnalysisPackages {
+		return nil
+	}
+
+	dir := path
+	if info, err := g.fs.Stat(path); err == nil && !info.IsDir() {
+		dir = DirPath(path)
+	}
+	start := time.Now()
+	index, err := loadPackages(dir)
+	if err != nil {
+		return err
+	}
+	g.logger.Debug("Loaded packages", "path", dir, "files", len(index.files), "duration", time.Since(start))
+	g.packages = index
+	return nil
+}
+
+// resolveImports resolves the imports of a file in packages analysis, and returns them
+// as written otherwise
+func (g *Goverhaul) resolveImports(goFilePath string, imports []importRef, module goModule) ([]importRef, goModule) {
+	if g.packages == nil {
+		return imports, module
+	}
+	resolved, module, ok := g.packages.resolve(goFilePath, imports, module)
+	if !ok {
+		g.logger.Debug("File is not in a loaded package, its imports are not resolved", "path", goFilePath)
+	}
+	return resolved, module
+}
+
+// resolve replaces the imports of a file by their resolution, and returns the module of the file.
+// Files unknown to the go command, such as files excluded by build constraints, keep their
+// imports and module as read from the source.
+func (i *packageIndex) resolve(goFilePath string, imports []importRef, module goModule) ([]importRef, goModule, bool) {
+	file, ok := i.files[AbsPath(goFilePath)]
+	if !ok {
+		return imports, module, false
+	}
+	if file.module != "" {
+		module.path = file.module
+	}
+
+	resolved := make([]importRef, len(imports))
+	for n, imp := range imports {
+		if r, ok := file.imports[imp.Path]; ok {
+			imp.Path, imp.Group = r.path, r.group
+		}
+		resolved[n] = imp
+	}
+	return resolved, module, true
+}
+
+// groupModule returns the module name an import is matched relative to: imports resolved
+// outside of the module of the file are not module-relative, even when their path starts with it
+func groupModule(imp importRef, moduleName string) string {
+	if imp.Group != "" && imp.Group != SelectorModule {
+		return ""
+	}
+	return moduleName
+}
@@ -0,0 +1,143 @@
+package goverhaul
+
+import (
+	"io"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePackagesTree writes a module whose go.mod replaces a nested module and a module
+// with a path that looks like the standard library, then moves to it
+func writePackagesTree(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("the go command is not available")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n\n" +
+			"require (\n\texample.com/app/tools v0.0.0\n\tlegacy v0.0.0\n)\n\n" +
+			"replace (\n\texample.com/app/tools => ./tools\n\tlegacy => ./third_party/legacy\n)\n",
+		"internal/domain/domain.go": "package domain\n\nimport (\n\t\"fmt\"\n\n" +
+			"\t\"example.com/app/internal/shared\"\n\t\"example.com/app/tools/gen\"\n\t\"legacy/log\"\n)\n\n" +
+			"var _ = fmt.Sprint(shared.Name, gen.Name, log.Name)\n",
+		"internal/shared/shared.go":       "package shared\n\nconst Name = \"shared\"\n",
+		"tools/go.mod":                    "module example.com/app/tools\n\ngo 1.24\n",
+		"tools/gen/gen.go":                "package gen\n\nconst Name = \"gen\"\n",
+		"third_party/legacy/go.mod":       "module legacy\n\ngo 1.24\n",
+		"third_party/legacy/log/log.go":   "package log\n\nconst Name = \"log\"\n",
+		"internal/domain/domain_plan9.go": "package domain\n\nimport \"legacy/log\"\n\nvar _ = log.Name\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, afero.NewOsFs().MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, afero.WriteFile(afero.NewOsFs(), path, []byte(content), 0o644))
+	}
+	t.Chdir(dir)
+}
+
+func TestLintPackagesAnalysis(t *testing.T) {
+	writePackagesTree(t)
+
+	rules := []Rule{{
+		Path:       "internal/domain",
+		Allowed:    []string{"@stdlib", "@module"},
+		Prohibited: []ProhibitedPkg{{Name: "tools/gen"}},
+	}}
+
+	tests := map[string]struct {
+		analysis AnalysisMode
+		expected []string // file:rule id of the violations
+	}{
+		"syntax analysis trusts the import paths": {
+			analysis: AnalysisSyntax,
+			// The nested module looks like a package of the module, and the replaced module like the standard library
+			expected: []string{"internal/domain/domain.go:internal/domain:prohibited:tools/gen"},
+		},
+		"packages analysis resolves the modules": {
+			analysis: AnalysisPackages,
+			// The plan9 file is not built on this platform, so its imports are read as written
+			expected: []string{
+				"internal/domain/domain.go:internal/domain:allowed",
+				"internal/domain/domain.go:internal/domain:allowed",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{Modfile: "go.mod", Rules: rules, Analysis: test.analysis}
+			linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), afero.NewOsFs())
+			require.NoError(t, err)
+
+			violations, err := linter.Lint("internal")
+			require.ErrorIs(t, err, ErrLint)
+
+			var actual []string
+			for _, v := range violations.Violations {
+				actual = append(actual, v.File+":"+v.RuleID)
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestPackageIndexResolve(t *testing.T) {
+	writePackagesTree(t)
+
+	index, err := loadPackages(".")
+	require.NoError(t, err)
+
+	imports := []importRef{
+		{Path: "fmt"},
+		{Path: "example.com/app/internal/shared"},
+		{Path: "example.com/app/tools/gen"},
+		{Path: "legacy/log"},
+	}
+	resolved, module, ok := index.resolve("internal/domain/domain.go", imports, goModule{root: "."})
+	require.True(t, ok)
+	assert.Equal(t, "example.com/app", module.path)
+	assert.Equal(t, []importRef{
+		{Path: "fmt", Group: SelectorStdlib},
+		{Path: "example.com/app/internal/shared", Group: SelectorModule},
+		{Path: "example.com/app/tools/gen", Group: SelectorThirdParty},
+		{Path: "legacy/log", Group: SelectorThirdParty},
+	}, resolved)
+
+	_, _, ok = index.resolve("internal/domain/domain_plan9.go", imports, goModule{})
+	assert.False(t, ok, "files excluded by build constraints are not loaded")
+}
+
+func TestValidateAnalysisMode(t *testing.T) {
+	assert.NoError(t, validateAnalysisMode("", afero.NewMemMapFs()))
+	assert.NoError(t, validateAnalysisMode(AnalysisPackages, afero.NewOsFs()))
+	assert.ErrorIs(t, validateAnalysisMode("types", nil), ErrConfig)
+	assert.ErrorIs(t, validateAnalysisMode(AnalysisPackages, afero.NewMemMapFs()), ErrConfig,
+		"the go command cannot read an in-memory file system")
+}
+
+func TestCacheFingerprintPackagesAnalysis(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "go.mod", []byte("module example.com/app\n"), 0o644))
+
+	cfg := Config{Modfile: "go.mod", Analysis: AnalysisPackages}
+	before, err := cacheFingerprint(cfg, memFs, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, afero.WriteFile(memFs, "go.mod",
+		[]byte("module example.com/app\n\nreplace legacy => ./legacy\n"), 0o644))
+	after, err := cacheFingerprint(cfg, memFs, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, before["modfile"], after["modfile"], "imports resolve against go.mod")
+
+	syntax, err := cacheFingerprint(Config{Modfile: "go.mod"}, memFs, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, syntax, "modfile")
+}
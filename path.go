@@ -34,9 +34,11 @@ func JoinPaths(elem ...string) string {
 // Both paths are normalized before comparison.
 // This function works on all operating systems by normalizing paths to use forward slashes.
 func IsSubPath(parentPath, childPath string) bool {
-	normalizedParent := NormalizePath(parentPath)
-	normalizedChild := NormalizePath(childPath)
+	return isSubDir(NormalizePath(parentPath), NormalizePath(childPath))
+}
 
+// isSubDir is IsSubPath for paths that are already normalized
+func isSubDir(normalizedParent, normalizedChild string) bool {
 	// Handle empty paths
 	if normalizedParent == "" || normalizedParent == "." {
 		return true // Empty parent means any path is a subpath
@@ -47,12 +49,10 @@ func IsSubPath(parentPath, childPath string) bool {
 		return true
 	}
 
-	// Ensure paths end with slash for proper prefix matching
-	if !strings.HasSuffix(normalizedParent, "/") {
-		normalizedParent += "/"
-	}
-
-	return strings.HasPrefix(normalizedChild, normalizedParent)
+	// Check for a path separator right after the parent prefix
+	return len(normalizedChild) > len(normalizedParent) &&
+		strings.HasPrefix(normalizedChild, normalizedParent) &&
+		(normalizedChild[len(normalizedParent)] == '/' || strings.HasSuffix(normalizedParent, "/"))
 }
 
 // IsAbsPath checks if a path is absolute
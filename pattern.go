@@ -0,0 +1,371 @@
+package goverhaul
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// Pattern syntax used by rule paths and import lists:
+//
+//   - a plain path such as "internal/db" matches exactly that path
+//   - "*" matches exactly one path segment: "internal/*/domain"
+//   - "**" matches zero or more path segments: "services/**/adapters"
+//   - "..." matches zero or more path segments, so "github.com/aws/aws-sdk-go-v2/..."
+//     matches the module root and every package below it
+//   - within a segment, the wildcards of path.Match ("*", "?", "[a-z]") match a part of the segment
+//   - in import lists, a leading "!" negates the entry: "!internal/shared"
+//   - in import lists, "@stdlib", "@module" and "@thirdparty" select a whole group of imports
+//
+// Import list entries also have a match mode (see MatchMode). Entries ending in "/..." without
+// other wildcards are subtree entries, entries with wildcards are patterns, and plain entries use
+// the default mode of the list. A "exact:", "subtree:" or "pattern:" prefix sets the mode explicitly.
+//
+// Entries whose first segment has no dot (e.g. "internal/db") are module-relative:
+// they match both the literal path and the path below the current module.
+
+// pathPattern is a compiled path pattern
+type pathPattern struct {
+	raw      string   // The pattern as written in the configuration
+	negated  bool     // Whether the pattern started with "!"
+	relative bool     // Whether the pattern is module-relative
+	literal  string   // The path to compare with, when the pattern has no wildcards
+	subtree  bool     // Whether a literal pattern also matches the paths below it
+	segments []string // The pattern split into path segments, when it has wildcards
+	selector string   // The import group selected by the entry ("@stdlib", ...), if any
+}
+
+// Import group selectors usable in import lists
+const (
+	// SelectorStdlib selects standard library imports: paths whose first segment has no dot
+	SelectorStdlib = "@stdlib"
+	// SelectorModule selects imports of the module the linted file belongs to,
+	// and of the other modules of its go.work workspace
+	SelectorModule = "@module"
+	// SelectorThirdParty selects every import that is neither standard library nor module
+	SelectorThirdParty = "@thirdparty"
+)
+
+// classifyImport returns the selector of the import group an import belongs to.
+// Imports of the other modules of a go.work workspace count as module imports.
+func classifyImport(imp, moduleName string, workspace ...string) string {
+	switch {
+	case inModule(imp, moduleName) || slices.ContainsFunc(workspace, func(m string) bool { return inModule(imp, m) }):
+		return SelectorModule
+	case !strings.Contains(strings.SplitN(imp, "/", 2)[0], "."):
+		return SelectorStdlib
+	default:
+		return SelectorThirdParty
+	}
+}
+
+// inModule reports whether an import path belongs to the given module
+func inModule(imp, moduleName string) bool {
+	return moduleName != "" && (imp == moduleName || strings.HasPrefix(imp, moduleName+"/"))
+}
+
+// compilePattern parses a pattern. Negation is handled by compileEntry, as it is only
+// meaningful in import lists.
+func compilePattern(raw string) (pathPattern, error) {
+	p := pathPattern{raw: raw}
+
+	pattern := strings.TrimSpace(raw)
+	if strings.HasPrefix(pattern, "!") {
+		return pathPattern{}, NewConfigError("negated pattern "+raw+" is only supported in import lists", nil)
+	}
+
+	pattern = strings.Trim(NormalizePath(pattern), "/")
+	if pattern == "" || pattern == "." {
+		return pathPattern{}, NewConfigError("empty pattern "+raw, nil)
+	}
+
+	segments := strings.Split(pattern, "/")
+	p.relative = !strings.Contains(segments[0], ".")
+
+	if !hasWildcard(pattern) {
+		p.literal = pattern
+		return p, nil
+	}
+
+	for _, segment := range segments {
+		if segment == "**" || segment == "..." {
+			continue
+		}
+		// path.Match reports malformed patterns regardless of the name it is matched against
+		if _, err := path.Match(segment, ""); err != nil {
+			return pathPattern{}, NewConfigError("invalid pattern "+raw, err)
+		}
+	}
+	p.segments = segments
+
+	return p, nil
+}
+
+// compileEntry parses an import list entry, resolving its match mode.
+// Plain entries without an explicit mode use defaultMode.
+func compileEntry(raw string, defaultMode MatchMode) (pathPattern, error) {
+	entry := strings.TrimSpace(raw)
+	negated := strings.HasPrefix(entry, "!")
+	entry = strings.TrimPrefix(entry, "!")
+
+	if strings.HasPrefix(entry, "@") {
+		switch entry {
+		case SelectorStdlib, SelectorModule, SelectorThirdParty:
+			return pathPattern{raw: raw, negated: negated, selector: entry}, nil
+		default:
+			return pathPattern{}, WithDetails(NewConfigError("unknown import selector "+entry, nil),
+				"Supported selectors: @stdlib, @module, @thirdparty")
+		}
+	}
+
+	mode := defaultMode
+	if explicit, rest, ok := cutMatchMode(entry); ok {
+		mode, entry = explicit, rest
+	} else if root, ok := strings.CutSuffix(entry, "/..."); ok && !hasWildcard(root) {
+		mode, entry = MatchSubtree, root
+	} else if hasWildcard(entry) {
+		mode = MatchPattern
+	}
+
+	var p pathPattern
+	switch mode {
+	case MatchPattern:
+		var err error
+		p, err = compilePattern(entry)
+		if err != nil {
+			return pathPattern{}, err
+		}
+	case MatchExact, MatchSubtree, "":
+		literal := strings.Trim(NormalizePath(entry), "/")
+		if literal == "" || literal == "." {
+			return pathPattern{}, NewConfigError("empty pattern "+raw, nil)
+		}
+		p = pathPattern{
+			literal:  literal,
+			subtree:  mode == MatchSubtree,
+			relative: !strings.Contains(strings.SplitN(literal, "/", 2)[0], "."),
+		}
+	default:
+		return pathPattern{}, NewConfigError("invalid match mode "+string(mode), nil)
+	}
+
+	p.raw = raw
+	p.negated = negated
+	return p, nil
+}
+
+// cutMatchMode splits an explicit "mode:" prefix from an entry
+func cutMatchMode(entry string) (MatchMode, string, bool) {
+	for _, mode := range []MatchMode{MatchExact, MatchSubtree, MatchPattern} {
+		if rest, ok := strings.CutPrefix(entry, string(mode)+":"); ok {
+			return mode, rest, true
+		}
+	}
+	return "", entry, false
+}
+
+// hasWildcard reports whether a pattern contains any wildcard syntax
+func hasWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[") || strings.Contains(pattern, "...")
+}
+
+// isLiteral reports whether the pattern matches a single path only
+func (p pathPattern) isLiteral() bool {
+	return p.segments == nil && p.selector == ""
+}
+
+// match reports whether the pattern matches the whole path, ignoring negation
+func (p pathPattern) match(name string) bool {
+	if p.isLiteral() {
+		if p.subtree {
+			return IsSubPath(p.literal, name)
+		}
+		return name == p.literal
+	}
+	return matchSegments(p.segments, strings.Split(name, "/"))
+}
+
+// matchPrefix reports whether the pattern matches the path or one of its parent directories
+func (p pathPattern) matchPrefix(name string) bool {
+	if p.isLiteral() {
+		return IsSubPath(p.literal, name)
+	}
+
+	segments := strings.Split(name, "/")
+	for i := len(segments); i > 0; i-- {
+		if matchSegments(p.segments, segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchedPrefix returns the shortest leading part of the path matched by the pattern
+func (p pathPattern) matchedPrefix(name string) (string, bool) {
+	if p.isLiteral() {
+		return p.literal, IsSubPath(p.literal, name)
+	}
+
+	segments := strings.Split(name, "/")
+	for i := 1; i <= len(segments); i++ {
+		if matchSegments(p.segments, segments[:i]) {
+			return strings.Join(segments[:i], "/"), true
+		}
+	}
+	return "", false
+}
+
+// matchSegments matches path segments against pattern segments
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		segment := pattern[0]
+		if segment == "**" || segment == "..." {
+			// Collapse consecutive multi-segment wildcards
+			rest := pattern[1:]
+			for len(rest) > 0 && (rest[0] == "**" || rest[0] == "...") {
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(segment, name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// patternList is an ordered list of import patterns where the last matching entry wins.
+// Exact and subtree entries are indexed in maps so that the common case is a few lookups.
+type patternList struct {
+	entries  []pathPattern
+	exact    map[string]int // literal path -> index of the last non-negated exact entry
+	subtree  map[string]int // subtree root -> index of the last non-negated subtree entry
+	wildcard []int          // indexes of entries that need pattern matching (wildcards or negation)
+}
+
+// compilePatternList compiles the given entries, returning the first invalid one as an error.
+// Plain entries are matched according to defaultMode.
+func compilePatternList(raw []string, defaultMode MatchMode) (patternList, error) {
+	list := patternList{
+		exact:   make(map[string]int),
+		subtree: make(map[string]int),
+	}
+	for _, entry := range raw {
+		p, err := compileEntry(entry, defaultMode)
+		if err != nil {
+			return patternList{}, err
+		}
+		list.add(p)
+	}
+	return list, nil
+}
+
+// add appends a compiled pattern to the list
+func (l *patternList) add(p pathPattern) {
+	index := len(l.entries)
+	l.entries = append(l.entries, p)
+	switch {
+	case p.negated || !p.isLiteral():
+		l.wildcard = append(l.wildcard, index)
+	case p.subtree:
+		l.subtree[p.literal] = index
+	default:
+		l.exact[p.literal] = index
+	}
+}
+
+// isEmpty reports whether the list has no entries
+func (l patternList) isEmpty() bool {
+	return len(l.entries) == 0
+}
+
+// onlyNegations reports whether every entry of the list is negated
+func (l patternList) onlyNegations() bool {
+	for _, p := range l.entries {
+		if !p.negated {
+			return false
+		}
+	}
+	return len(l.entries) > 0
+}
+
+// match returns the index of the entry deciding the import and whether it is a positive match.
+// The import is also tried relative to moduleName for module-relative entries, and workspace
+// lists the other modules of the go.work workspace for the "@module" selector.
+// It returns -1 when no entry matches.
+func (l patternList) match(imp, moduleName string, workspace ...string) (int, bool) {
+	return l.matchGroup(imp, moduleName, "", workspace)
+}
+
+// matchGroup is match for an import whose group selector is already known, from package
+// analysis. When group is empty, the group is derived from the import path like match does.
+func (l patternList) matchGroup(imp, moduleName, group string, workspace []string) (int, bool) {
+	relative, hasRelative := strings.CutPrefix(imp, moduleName+"/")
+	hasRelative = hasRelative && moduleName != ""
+
+	best := l.lookup(imp, false)
+	if hasRelative {
+		best = max(best, l.lookup(relative, true))
+	}
+
+	for _, index := range l.wildcard {
+		if index <= best {
+			continue
+		}
+		p := l.entries[index]
+		if p.selector != "" {
+			if group == "" {
+				group = classifyImport(imp, moduleName, workspace...)
+			}
+			if group == p.selector {
+				best = index
+			}
+			continue
+		}
+		if p.match(imp) || (hasRelative && p.relative && p.match(relative)) {
+			best = index
+		}
+	}
+
+	if best < 0 {
+		return -1, false
+	}
+	return best, !l.entries[best].negated
+}
+
+// lookup returns the index of the last exact or subtree entry matching the path, or -1.
+// When relativeOnly is set, only module-relative entries are considered.
+func (l patternList) lookup(name string, relativeOnly bool) int {
+	best := -1
+	if index, ok := l.exact[name]; ok && (!relativeOnly || l.entries[index].relative) {
+		best = index
+	}
+	if len(l.subtree) == 0 {
+		return best
+	}
+
+	// Walk up the path, looking for a subtree entry at each level
+	for prefix := name; ; {
+		if index, ok := l.subtree[prefix]; ok && index > best && (!relativeOnly || l.entries[index].relative) {
+			best = index
+		}
+		slash := strings.LastIndex(prefix, "/")
+		if slash < 0 {
+			return best
+		}
+		prefix = prefix[:slash]
+	}
+}
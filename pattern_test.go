@@ -0,0 +1,235 @@
+package goverhaul
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathPatternMatch(t *testing.T) {
+	tests := map[string]struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		"literal matches itself":                  {pattern: "internal/db", path: "internal/db", expected: true},
+		"literal does not match subpackage":       {pattern: "internal/db", path: "internal/db/sql", expected: false},
+		"literal does not match suffix":           {pattern: "internal/db", path: "foo/internal/db", expected: false},
+		"star matches one segment":                {pattern: "internal/*/domain", path: "internal/user/domain", expected: true},
+		"star does not match zero segments":       {pattern: "internal/*/domain", path: "internal/domain", expected: false},
+		"star does not match two segments":        {pattern: "internal/*/domain", path: "internal/a/b/domain", expected: false},
+		"double star matches zero segments":       {pattern: "services/**/adapters", path: "services/adapters", expected: true},
+		"double star matches many segments":       {pattern: "services/**/adapters", path: "services/user/http/adapters", expected: true},
+		"double star requires the tail":           {pattern: "services/**/adapters", path: "services/user/http", expected: false},
+		"dots match the root":                     {pattern: "github.com/aws/aws-sdk-go-v2/...", path: "github.com/aws/aws-sdk-go-v2", expected: true},
+		"dots match packages below the root":      {pattern: "github.com/aws/aws-sdk-go-v2/...", path: "github.com/aws/aws-sdk-go-v2/service/s3", expected: true},
+		"dots do not match sibling modules":       {pattern: "github.com/aws/aws-sdk-go-v2/...", path: "github.com/aws/aws-sdk-go", expected: false},
+		"segment wildcard matches part":           {pattern: "internal/db*", path: "internal/dbutil", expected: true},
+		"segment wildcard does not cross slashes": {pattern: "internal/db*", path: "internal/db/util", expected: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := compilePattern(test.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, p.match(test.path))
+		})
+	}
+}
+
+func TestPathPatternMatchPrefix(t *testing.T) {
+	p, err := compilePattern("services/*/adapters")
+	require.NoError(t, err)
+
+	assert.True(t, p.matchPrefix("services/user/adapters"))
+	assert.True(t, p.matchPrefix("services/user/adapters/http"))
+	assert.False(t, p.matchPrefix("services/user"))
+	assert.False(t, p.matchPrefix("pkg/services/user/adapters"))
+}
+
+func TestCompilePatternErrors(t *testing.T) {
+	tests := map[string]struct {
+		pattern string
+	}{
+		"empty pattern":           {pattern: ""},
+		"malformed character set": {pattern: "internal/[a-"},
+		"negated pattern":         {pattern: "!internal/db"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := compilePattern(test.pattern)
+			assert.ErrorIs(t, err, ErrConfig)
+		})
+	}
+}
+
+func TestCompileEntry(t *testing.T) {
+	tests := map[string]struct {
+		entry       string
+		defaultMode MatchMode
+		matches     []string
+		mismatches  []string
+	}{
+		"plain entry in exact mode": {
+			entry: "encoding", defaultMode: MatchExact,
+			matches: []string{"encoding"}, mismatches: []string{"encoding/json"},
+		},
+		"plain entry in subtree mode": {
+			entry: "encoding", defaultMode: MatchSubtree,
+			matches: []string{"encoding", "encoding/json"}, mismatches: []string{"encodings"},
+		},
+		"dots make a subtree entry": {
+			entry: "internal/domain/...", defaultMode: MatchExact,
+			matches: []string{"internal/domain", "internal/domain/user"}, mismatches: []string{"internal/domainx"},
+		},
+		"wildcards make a pattern entry": {
+			entry: "internal/*/domain", defaultMode: MatchExact,
+			matches: []string{"internal/user/domain"}, mismatches: []string{"internal/user/domain/model"},
+		},
+		"explicit exact mode overrides the default": {
+			entry: "exact:encoding", defaultMode: MatchSubtree,
+			matches: []string{"encoding"}, mismatches: []string{"encoding/json"},
+		},
+		"explicit subtree mode": {
+			entry: "subtree:internal/domain", defaultMode: MatchExact,
+			matches: []string{"internal/domain/user"}, mismatches: []string{"internal/app"},
+		},
+		"explicit pattern mode": {
+			entry: "pattern:internal/**/model", defaultMode: MatchExact,
+			matches: []string{"internal/user/model"}, mismatches: []string{"internal/user"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := compileEntry(test.entry, test.defaultMode)
+			require.NoError(t, err)
+			for _, path := range test.matches {
+				assert.True(t, p.match(path), "expected %s to match %s", test.entry, path)
+			}
+			for _, path := range test.mismatches {
+				assert.False(t, p.match(path), "expected %s not to match %s", test.entry, path)
+			}
+		})
+	}
+}
+
+func TestCompileEntryErrors(t *testing.T) {
+	for _, entry := range []string{"", "!", "subtree:", "pattern:internal/[a-"} {
+		_, err := compileEntry(entry, MatchExact)
+		assert.ErrorIs(t, err, ErrConfig, "entry %q", entry)
+	}
+
+	_, err := compileEntry("internal", MatchMode("fuzzy"))
+	assert.ErrorIs(t, err, ErrConfig)
+}
+
+func TestPatternListMatch(t *testing.T) {
+	const module = "example.com/app"
+
+	tests := map[string]struct {
+		entries       []string
+		imp           string
+		expectedIndex int
+		expectedMatch bool
+	}{
+		"exact entry": {
+			entries: []string{"fmt", "os"}, imp: "os",
+			expectedIndex: 1, expectedMatch: true,
+		},
+		"module-relative entry": {
+			entries: []string{"internal/db"}, imp: "example.com/app/internal/db",
+			expectedIndex: 0, expectedMatch: true,
+		},
+		"module-relative entry does not match other modules": {
+			entries: []string{"internal/db"}, imp: "example.com/other/internal/db",
+			expectedIndex: -1, expectedMatch: false,
+		},
+		"full path entry is not module-relative": {
+			entries: []string{"github.com/lib/pq"}, imp: "example.com/app/github.com/lib/pq",
+			expectedIndex: -1, expectedMatch: false,
+		},
+		"module-relative pattern": {
+			entries: []string{"internal/*/domain"}, imp: "example.com/app/internal/user/domain",
+			expectedIndex: 0, expectedMatch: true,
+		},
+		"later negation wins": {
+			entries: []string{"internal/...", "!internal/shared"}, imp: "example.com/app/internal/shared",
+			expectedIndex: 1, expectedMatch: false,
+		},
+		"negation does not affect other paths": {
+			entries: []string{"internal/...", "!internal/shared"}, imp: "example.com/app/internal/db",
+			expectedIndex: 0, expectedMatch: true,
+		},
+		"module-relative subtree entry": {
+			entries: []string{"fmt", "internal/domain/..."}, imp: "example.com/app/internal/domain/user",
+			expectedIndex: 1, expectedMatch: true,
+		},
+		"later subtree negation wins": {
+			entries: []string{"internal/...", "!internal/legacy/..."}, imp: "internal/legacy/db",
+			expectedIndex: 1, expectedMatch: false,
+		},
+		"later positive entry wins over negation": {
+			entries: []string{"!internal/shared", "internal/shared"}, imp: "internal/shared",
+			expectedIndex: 1, expectedMatch: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			list, err := compilePatternList(test.entries, MatchExact)
+			require.NoError(t, err)
+
+			index, ok := list.match(test.imp, module)
+			assert.Equal(t, test.expectedIndex, index)
+			assert.Equal(t, test.expectedMatch, ok)
+		})
+	}
+}
+
+func TestClassifyImport(t *testing.T) {
+	const module = "example.com/app"
+
+	tests := map[string]struct {
+		imp      string
+		expected string
+	}{
+		"standard library package":  {imp: "fmt", expected: SelectorStdlib},
+		"nested standard library":   {imp: "encoding/json", expected: SelectorStdlib},
+		"module root":               {imp: "example.com/app", expected: SelectorModule},
+		"module package":            {imp: "example.com/app/internal/domain", expected: SelectorModule},
+		"module with shared prefix": {imp: "example.com/application", expected: SelectorThirdParty},
+		"third-party package":       {imp: "github.com/stretchr/testify/assert", expected: SelectorThirdParty},
+		"golang.org extension":      {imp: "golang.org/x/mod/modfile", expected: SelectorThirdParty},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, classifyImport(test.imp, module))
+		})
+	}
+}
+
+func TestPatternListSelectors(t *testing.T) {
+	const module = "example.com/app"
+
+	list, err := compilePatternList([]string{"@stdlib", "internal/domain/...", "!unsafe"}, MatchExact)
+	require.NoError(t, err)
+
+	for imp, expected := range map[string]bool{
+		"fmt":                                  true,
+		"net/http":                             true,
+		"unsafe":                               false,
+		"example.com/app/internal/domain/user": true,
+		"example.com/app/internal/infra":       false,
+		"github.com/lib/pq":                    false,
+	} {
+		_, ok := list.match(imp, module)
+		assert.Equal(t, expected, ok, "import %s", imp)
+	}
+
+	_, err = compilePatternList([]string{"@vendor"}, MatchExact)
+	assert.ErrorIs(t, err, ErrConfig)
+}
@@ -0,0 +1,146 @@
+package goverhaul
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Reporter writes the result of a lint run in a specific output format
+type Reporter interface {
+	Report(w io.Writer, lv *LintViolations, summary RunSummary) error
+}
+
+// ReporterFunc adapts an ordinary function to the Reporter interface
+type ReporterFunc func(w io.Writer, lv *LintViolations, summary RunSummary) error
+
+// Report calls f(w, lv, summary)
+func (f ReporterFunc) Report(w io.Writer, lv *LintViolations, summary RunSummary) error {
+	return f(w, lv, summary)
+}
+
+// ReporterFactory creates a Reporter for the configuration of the run.
+// Reporters that don't depend on the configuration can ignore it.
+type ReporterFactory func(cfg Config) Reporter
+
+var (
+	reportersMu sync.RWMutex
+	reporters   = map[string]ReporterFactory{
+		"text":  func(Config) Reporter { return TextReporter{} },
+		"json":  func(Config) Reporter { return JSONReporter{} },
+		"sarif": func(cfg Config) Reporter { return SARIFReporter{Rules: cfg.EffectiveRules()} },
+	}
+)
+
+// RegisterReporter makes a reporter available under the given format name.
+// Registering a format that already exists replaces it, including the built-in ones.
+func RegisterReporter(format string, factory ReporterFactory) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		return NewConfigError("reporter format name must not be empty", nil)
+	}
+	if factory == nil {
+		return NewConfigError("reporter factory for format "+format+" must not be nil", nil)
+	}
+
+	reportersMu.Lock()
+	defer reportersMu.Unlock()
+	reporters[format] = factory
+	return nil
+}
+
+// NewReporter creates the reporter registered under the given format name
+func NewReporter(format string, cfg Config) (Reporter, error) {
+	reportersMu.RLock()
+	factory, ok := reporters[strings.ToLower(strings.TrimSpace(format))]
+	reportersMu.RUnlock()
+
+	if !ok {
+		return nil, WithDetails(NewConfigError("unsupported output format "+format, nil),
+			"Supported formats: "+strings.Join(ReporterFormats(), ", "))
+	}
+	return factory(cfg), nil
+}
+
+// ReporterFormats returns the names of all registered formats in lexical order
+func ReporterFormats() []string {
+	reportersMu.RLock()
+	defer reportersMu.RUnlock()
+
+	formats := make([]string, 0, len(reporters))
+	for format := range reporters {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// TextReporter writes the human-readable report, grouped by file or by rule
+type TextReporter struct {
+	GroupByRule bool
+}
+
+// Report implements the Reporter interface
+func (r TextReporter) Report(w io.Writer, lv *LintViolations, summary RunSummary) error {
+	text := lv.PrintByFile()
+	if r.GroupByRule {
+		text = lv.PrintByRule()
+	}
+	if _, err := fmt.Fprintln(w, text); err != nil {
+		return err
+	}
+
+	msg := ""
+	if len(summary.Suppressions) > 0 {
+		msg += fmt.Sprintf("%d suppressions applied:\n", len(summary.Suppressions))
+		for _, s := range summary.Suppressions {
+			msg += fmt.Sprintf("  - File: %s:%d, Rule: %s, Reason: %s\n", s.File, s.Line, s.RuleID, s.Reason)
+		}
+		msg += "\n"
+	}
+	if len(summary.FixedBaselineEntries) > 0 {
+		msg += fmt.Sprintf("%d baseline entries are fixed and can be removed (run: goverhaul baseline write):\n",
+			len(summary.FixedBaselineEntries))
+		for _, entry := range summary.FixedBaselineEntries {
+			msg += fmt.Sprintf("  - File: %s, Import: %s, Rule: %s\n", entry.File, entry.Import, entry.Rule)
+		}
+		msg += "\n"
+	}
+	if msg == "" {
+		return nil
+	}
+	_, err := fmt.Fprint(w, msg)
+	return err
+}
+
+// JSONReporter writes the violations and the run summary as JSON, see WriteJSON
+type JSONReporter struct{}
+
+// Report implements the Reporter interface
+func (JSONReporter) Report(w io.Writer, lv *LintViolations, summary RunSummary) error {
+	return WriteJSON(w, lv, summary)
+}
+
+// jsonReport is the document written by WriteJSON
+type jsonReport struct {
+	Violations []LintViolation `json:"violations"`
+	Summary    RunSummary      `json:"summary"`
+}
+
+// WriteJSON writes the violations and the run summary as an indented JSON document.
+// Violations are written in a stable order (file, position, import, rule).
+func WriteJSON(w io.Writer, lv *LintViolations, summary RunSummary) error {
+	report := jsonReport{
+		Violations: make([]LintViolation, 0, len(lv.Violations)),
+		Summary:    summary,
+	}
+	report.Violations = append(report.Violations, lv.Violations...)
+	sortViolations(report.Violations)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
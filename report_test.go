@@ -0,0 +1,127 @@
+package goverhaul
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	lv := NewLintViolations()
+	lv.Add(LintViolation{File: "pkg/b.go", Line: 3, Import: "unsafe", Rule: "pkg"})
+	lv.Add(LintViolation{File: "pkg/a.go", Line: 7, Import: "os", Rule: "pkg"})
+	lv.Add(LintViolation{File: "pkg/a.go", Line: 4, Import: "net", Rule: "pkg"})
+
+	summary := RunSummary{
+		FilesScanned:   3,
+		FilesCached:    1,
+		RulesEvaluated: 2,
+		Duration:       time.Second,
+		Version:        "v1.0.0",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, lv, summary))
+
+	var report jsonReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, summary, report.Summary)
+	require.Len(t, report.Violations, 3)
+	assert.Equal(t, "net", report.Violations[0].Import)
+	assert.Equal(t, "os", report.Violations[1].Import)
+	assert.Equal(t, "unsafe", report.Violations[2].Import)
+
+	// The original collection is left untouched
+	assert.Equal(t, "pkg/b.go", lv.Violations[0].File)
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, NewLintViolations(), RunSummary{}))
+	assert.Contains(t, buf.String(), `"violations": []`)
+}
+
+func TestNewReporter(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		expected Reporter
+	}{
+		"should create text reporter":  {format: "text", expected: TextReporter{}},
+		"should create json reporter":  {format: "JSON", expected: JSONReporter{}},
+		"should create sarif reporter": {format: "sarif", expected: SARIFReporter{Rules: []Rule{{Path: "pkg"}}}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reporter, err := NewReporter(test.format, Config{Rules: []Rule{{Path: "pkg"}}})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, reporter)
+		})
+	}
+
+	t.Run("should reject unknown format", func(t *testing.T) {
+		_, err := NewReporter("yaml", Config{})
+		assert.ErrorIs(t, err, ErrConfig)
+	})
+}
+
+func TestRegisterReporter(t *testing.T) {
+	markdown := ReporterFunc(func(w io.Writer, lv *LintViolations, summary RunSummary) error {
+		for _, v := range lv.Violations {
+			fmt.Fprintf(w, "- `%s` imports `%s`\n", v.File, v.Import)
+		}
+		return nil
+	})
+	require.NoError(t, RegisterReporter("markdown", func(Config) Reporter { return markdown }))
+	t.Cleanup(func() {
+		reportersMu.Lock()
+		delete(reporters, "markdown")
+		reportersMu.Unlock()
+	})
+
+	assert.Contains(t, ReporterFormats(), "markdown")
+
+	reporter, err := NewReporter("markdown", Config{})
+	require.NoError(t, err)
+
+	lv := NewLintViolations()
+	lv.Add(LintViolation{File: "main.go", Import: "unsafe"})
+
+	var buf bytes.Buffer
+	require.NoError(t, reporter.Report(&buf, lv, RunSummary{}))
+	assert.Equal(t, "- `main.go` imports `unsafe`\n", buf.String())
+
+	assert.ErrorIs(t, RegisterReporter("", func(Config) Reporter { return markdown }), ErrConfig)
+	assert.ErrorIs(t, RegisterReporter("nil", nil), ErrConfig)
+}
+
+func TestTextReporter(t *testing.T) {
+	lv := NewLintViolations()
+	lv.Add(LintViolation{File: "main.go", Import: "unsafe", Rule: "cmd"})
+
+	var byFile, byRule bytes.Buffer
+	require.NoError(t, TextReporter{}.Report(&byFile, lv, RunSummary{}))
+	require.NoError(t, TextReporter{GroupByRule: true}.Report(&byRule, lv, RunSummary{}))
+
+	assert.Equal(t, lv.PrintByFile()+"\n", byFile.String())
+	assert.Equal(t, lv.PrintByRule()+"\n", byRule.String())
+}
+
+func TestTextReporterFixedBaselineEntries(t *testing.T) {
+	summary := RunSummary{FixedBaselineEntries: []BaselineEntry{
+		{File: "internal/api/api.go", Import: "example.com/internal/db", Rule: "internal/api:prohibited:internal/db"},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, TextReporter{}.Report(&buf, NewLintViolations(), summary))
+
+	assert.Contains(t, buf.String(), "1 baseline entries are fixed and can be removed")
+	assert.Contains(t, buf.String(), "File: internal/api/api.go, Import: example.com/internal/db, Rule: internal/api:prohibited:internal/db")
+}
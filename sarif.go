@@ -0,0 +1,233 @@
+package goverhaul
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifInfoURI = "https://github.com/gophersatwork/goverhaul"
+)
+
+// SARIF 2.1.0 document types, limited to the properties goverhaul produces
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string                     `json:"name"`
+		Version        string                     `json:"version,omitempty"`
+		InformationURI string                     `json:"informationUri"`
+		Rules          []sarifReportingDescriptor `json:"rules"`
+	}
+
+	sarifReportingDescriptor struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		FullDescription      *sarifMessage      `json:"fullDescription,omitempty"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+		EndLine     int `json:"endLine,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+)
+
+// SARIFReporter writes the violations as a SARIF 2.1.0 log, see WriteSARIF
+type SARIFReporter struct {
+	Rules []Rule // The configured rules, described in the log's tool section
+}
+
+// Report implements the Reporter interface
+func (r SARIFReporter) Report(w io.Writer, lv *LintViolations, summary RunSummary) error {
+	return WriteSARIF(w, lv, summary, r.Rules)
+}
+
+// WriteSARIF writes the violations as a SARIF 2.1.0 log.
+// Every configured rule contributes one reporting descriptor per prohibited entry and
+// one for its allow-list, and every violation becomes a result pointing at the offending import.
+func WriteSARIF(w io.Writer, lv *LintViolations, summary RunSummary, rules []Rule) error {
+	descriptors := make([]sarifReportingDescriptor, 0)
+	index := make(map[string]int)
+	addDescriptor := func(d sarifReportingDescriptor) {
+		if _, exists := index[d.ID]; exists {
+			return
+		}
+		index[d.ID] = len(descriptors)
+		descriptors = append(descriptors, d)
+	}
+
+	for _, rule := range rules {
+		for _, prohibited := range rule.Prohibited {
+			// Negated entries only lift prohibitions, they are never reported
+			if strings.HasPrefix(prohibited.Name, "!") {
+				continue
+			}
+			addDescriptor(prohibitedDescriptor(rule, prohibited))
+		}
+		if len(rule.Allowed) > 0 {
+			addDescriptor(allowedDescriptor(rule))
+		}
+	}
+
+	violations := make([]LintViolation, 0, len(lv.Violations))
+	violations = append(violations, lv.Violations...)
+	sortViolations(violations)
+
+	results := make([]sarifResult, 0, len(violations))
+	for _, v := range violations {
+		ruleID := v.RuleID
+		if ruleID == "" {
+			ruleID = v.Rule
+		}
+		if _, exists := index[ruleID]; !exists {
+			// The violation comes from a rule that is no longer configured (e.g. a cached result)
+			addDescriptor(sarifReportingDescriptor{
+				ID:                   ruleID,
+				ShortDescription:     sarifMessage{Text: "Import rule for " + v.Rule},
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(v.Severity)},
+			})
+		}
+
+		message := v.Cause
+		if message == "" {
+			message = v.Details
+		} else if len(v.Chain) > 0 {
+			message += " (" + strings.Join(v.Chain, " -> ") + ")"
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: index[ruleID],
+			Level:     sarifLevel(v.Severity),
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocationOf(v)}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "goverhaul",
+				Version:        summary.Version,
+				InformationURI: sarifInfoURI,
+				Rules:          descriptors,
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// prohibitedDescriptor describes a prohibited entry of a rule
+func prohibitedDescriptor(rule Rule, prohibited ProhibitedPkg) sarifReportingDescriptor {
+	d := sarifReportingDescriptor{
+		ID:                   prohibitedRuleID(rule, prohibited),
+		ShortDescription:     sarifMessage{Text: rule.Path + " must not import " + prohibited.Name},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(prohibitedSeverity(rule, prohibited))},
+	}
+	if description := cmp.Or(prohibited.Description, rule.Description, prohibited.Cause); description != "" {
+		d.FullDescription = &sarifMessage{Text: description}
+	}
+	return d
+}
+
+// allowedDescriptor describes the allow-list of a rule
+func allowedDescriptor(rule Rule) sarifReportingDescriptor {
+	return sarifReportingDescriptor{
+		ID:               allowedRuleID(rule),
+		ShortDescription: sarifMessage{Text: rule.Path + " may only import allowed packages"},
+		FullDescription: &sarifMessage{
+			Text: cmp.Or(rule.Description, "Allowed imports: "+strings.Join(rule.Allowed, ", ")),
+		},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(allowedSeverity(rule))},
+	}
+}
+
+// sarifPhysicalLocationOf returns the location of the offending import
+func sarifPhysicalLocationOf(v LintViolation) sarifPhysicalLocation {
+	location := sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{
+			URI:       NormalizePath(v.File),
+			URIBaseID: "%SRCROOT%",
+		},
+	}
+	if IsAbsPath(v.File) {
+		location.ArtifactLocation = sarifArtifactLocation{URI: "file://" + NormalizePath(v.File)}
+	}
+	if v.Line > 0 {
+		location.Region = &sarifRegion{
+			StartLine:   v.Line,
+			StartColumn: v.Column,
+			EndLine:     v.EndLine,
+			EndColumn:   v.EndColumn,
+		}
+	}
+	return location
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity Severity) string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,93 @@
+package goverhaul
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	rules := []Rule{
+		{
+			Path:    "internal/api",
+			Allowed: []string{"fmt", "net/http"},
+			Prohibited: []ProhibitedPkg{
+				{Name: "internal/database", Cause: "APIs should access database through domain services"},
+			},
+		},
+	}
+
+	lv := NewLintViolations()
+	lv.Add(LintViolation{
+		File:      "internal/api/api.go",
+		Line:      4,
+		Column:    2,
+		EndLine:   4,
+		EndColumn: 38,
+		Import:    "example.com/internal/database",
+		Rule:      "internal/api",
+		RuleID:    prohibitedRuleID(rules[0], rules[0].Prohibited[0]),
+		Cause:     "APIs should access database through domain services",
+		Severity:  SeverityError,
+	})
+	lv.Add(LintViolation{
+		File:     "internal/api/handler.go",
+		Import:   "os",
+		Rule:     "internal/api",
+		RuleID:   allowedRuleID(rules[0]),
+		Details:  "This import is not in the allowed list for this package",
+		Severity: SeverityWarning,
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, lv, RunSummary{Version: "v1.0.0"}, rules))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "goverhaul", run.Tool.Driver.Name)
+	assert.Equal(t, "v1.0.0", run.Tool.Driver.Version)
+
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "internal/api:prohibited:internal/database", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "APIs should access database through domain services", run.Tool.Driver.Rules[0].FullDescription.Text)
+	assert.Equal(t, "internal/api:allowed", run.Tool.Driver.Rules[1].ID)
+
+	require.Len(t, run.Results, 2)
+	prohibited := run.Results[0]
+	assert.Equal(t, "internal/api:prohibited:internal/database", prohibited.RuleID)
+	assert.Equal(t, 0, prohibited.RuleIndex)
+	assert.Equal(t, "error", prohibited.Level)
+	assert.Equal(t, "APIs should access database through domain services", prohibited.Message.Text)
+	location := prohibited.Locations[0].PhysicalLocation
+	assert.Equal(t, "internal/api/api.go", location.ArtifactLocation.URI)
+	assert.Equal(t, &sarifRegion{StartLine: 4, StartColumn: 2, EndLine: 4, EndColumn: 38}, location.Region)
+
+	notAllowed := run.Results[1]
+	assert.Equal(t, "internal/api:allowed", notAllowed.RuleID)
+	assert.Equal(t, 1, notAllowed.RuleIndex)
+	assert.Equal(t, "warning", notAllowed.Level)
+	assert.Equal(t, "This import is not in the allowed list for this package", notAllowed.Message.Text)
+	assert.Nil(t, notAllowed.Locations[0].PhysicalLocation.Region)
+}
+
+func TestWriteSARIFUnknownRule(t *testing.T) {
+	lv := NewLintViolations()
+	lv.Add(LintViolation{File: "main.go", Import: "unsafe", Rule: "cmd", Cause: "no unsafe"})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, lv, RunSummary{}, nil))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	require.Len(t, log.Runs[0].Tool.Driver.Rules, 1)
+	assert.Equal(t, "cmd", log.Runs[0].Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "cmd", log.Runs[0].Results[0].RuleID)
+}
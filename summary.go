@@ -0,0 +1,57 @@
+package goverhaul
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// modulePath is the import path of the goverhaul module
+const modulePath = "github.com/gophersatwork/goverhaul"
+
+// Version is the goverhaul version reported in run summaries.
+// It can be set at build time with -ldflags "-X github.com/gophersatwork/goverhaul.Version=vX.Y.Z",
+// otherwise it is read from the build information.
+var Version = ""
+
+// RunSummary describes a single Lint run
+type RunSummary struct {
+	FilesScanned int `json:"files_scanned"` // Go files visited during the run
+	FilesCached  int `json:"files_cached"`  // Go files whose results were served from the cache
+	// Cache lookups of incremental runs: files cached as clean, files cached with violations
+	// and files that had to be linted
+	CacheHitsClean      int           `json:"cache_hits_clean"`
+	CacheHitsViolations int           `json:"cache_hits_violations"`
+	CacheMisses         int           `json:"cache_misses"`
+	RulesEvaluated      int           `json:"rules_evaluated"` // Rules checked against at least one visited file
+	Duration            time.Duration `json:"duration_ns"`     // Wall-clock duration of the run
+	Version             string        `json:"version"`         // The goverhaul version that produced the run
+	Baselined           int           `json:"baselined"`       // Violations hidden by the baseline
+	// Generated files that were not linted, see GeneratedPolicy
+	GeneratedSkipped int `json:"generated_skipped"`
+	// Baseline entries that no longer match a violation and can be removed from the baseline
+	FixedBaselineEntries []BaselineEntry `json:"fixed_baseline_entries,omitempty"`
+	// Go files that could not be parsed, and were therefore not checked
+	UnparsedFiles []string `json:"unparsed_files,omitempty"`
+	// Suppression comments that hid violations during the run
+	Suppressions []Suppression `json:"suppressions,omitempty"`
+}
+
+// toolVersion returns the goverhaul version, falling back to the build information
+func toolVersion() string {
+	if Version != "" {
+		return Version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				return dep.Version
+			}
+		}
+	}
+
+	return "dev"
+}
@@ -0,0 +1,152 @@
+package goverhaul
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// suppressionDirective starts a comment suppressing violations:
+//
+//	//goverhaul:ignore <rule-id> <reason>
+//
+// Attached to an import spec, either on the line above it or at the end of its line, the comment
+// suppresses the violations of that import. Placed above the package clause, it suppresses the
+// violations of the whole file. The rule id is a RuleID, such as "internal/api:prohibited:internal/db",
+// or a rule path to suppress every check of the rule.
+const suppressionDirective = "//goverhaul:ignore"
+
+// suppressionRule is the rule of the violations reported for invalid or unused suppressions
+const suppressionRule = "goverhaul:ignore"
+
+// Suppression is a //goverhaul:ignore comment accepting violations
+type Suppression struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Import string `json:"import,omitempty"` // The import the comment is attached to, empty for the whole file
+	RuleID string `json:"rule_id"`          // The RuleID or rule path of the suppressed violations
+	Reason string `json:"reason"`           // Why the violations are accepted
+}
+
+// matches reports whether the suppression applies to a violation. A suppression attached to an
+// import also applies to the violations of the packages reached through it.
+func (s Suppression) matches(v LintViolation) bool {
+	if s.Import != "" && s.Import != v.Import && (len(v.Chain) == 0 || s.Import != v.Chain[0]) {
+		return false
+	}
+	return s.RuleID == v.RuleID || s.RuleID == v.Rule
+}
+
+// fileSuppressions returns the suppression comments of a parsed file:
+// the ones above the package clause and the ones attached to import specs
+func fileSuppressions(fset *token.FileSet, file *ast.File) []Suppression {
+	var suppressions []Suppression
+	for _, group := range file.Comments {
+		if group.End() < file.Package {
+			suppressions = append(suppressions, parseSuppressions(fset, group, "")...)
+		}
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			path := strings.Trim(imp.Path.Value, `"`)
+			doc := imp.Doc
+			if doc == nil && !gen.Lparen.IsValid() {
+				// The doc comment of `import "path"` belongs to the declaration
+				doc = gen.Doc
+			}
+			suppressions = append(suppressions, parseSuppressions(fset, doc, path)...)
+			suppressions = append(suppressions, parseSuppressions(fset, imp.Comment, path)...)
+		}
+	}
+
+	return suppressions
+}
+
+// parseSuppressions parses the suppression directives of a comment group
+func parseSuppressions(fset *token.FileSet, group *ast.CommentGroup, imp string) []Suppression {
+	if group == nil {
+		return nil
+	}
+
+	var suppressions []Suppression
+	for _, comment := range group.List {
+		rest, ok := strings.CutPrefix(comment.Text, suppressionDirective)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+
+		position := fset.Position(comment.Pos())
+		s := Suppression{
+			File:   NormalizePath(position.Filename),
+			Line:   position.Line,
+			Import: imp,
+		}
+		fields := strings.Fields(rest)
+		if len(fields) > 0 {
+			s.RuleID = fields[0]
+			s.Reason = strings.Join(fields[1:], " ")
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions
+}
+
+// applySuppressions removes the suppressed violations. It returns the remaining violations,
+// followed by warnings for suppressions that have no rule id or reason, or that suppress nothing,
+// and the suppressions that were applied.
+func applySuppressions(violations []LintViolation, suppressions []Suppression) ([]LintViolation, []Suppression) {
+	if len(suppressions) == 0 {
+		return violations, nil
+	}
+
+	used := make([]bool, len(suppressions))
+	remaining := make([]LintViolation, 0, len(violations))
+	for _, v := range violations {
+		suppressed := false
+		for i, s := range suppressions {
+			if s.RuleID != "" && s.Reason != "" && s.matches(v) {
+				used[i] = true
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			remaining = append(remaining, v)
+		}
+	}
+
+	var applied []Suppression
+	for i, s := range suppressions {
+		switch {
+		case s.RuleID == "":
+			remaining = append(remaining, suppressionViolation(s, "Suppression has no rule id",
+				"Use: "+suppressionDirective+" <rule-id> <reason>"))
+		case s.Reason == "":
+			remaining = append(remaining, suppressionViolation(s,
+				fmt.Sprintf("Suppression of %s has no reason", s.RuleID),
+				"Explain why the violation is accepted: "+suppressionDirective+" "+s.RuleID+" <reason>"))
+		case !used[i]:
+			remaining = append(remaining, suppressionViolation(s,
+				fmt.Sprintf("Suppression of %s is unused", s.RuleID),
+				"No violation matches the suppression, remove it"))
+		default:
+			applied = append(applied, s)
+		}
+	}
+
+	return remaining, applied
+}
+
+// suppressionViolation creates the warning reported for an invalid or unused suppression
+func suppressionViolation(s Suppression, cause, details string) LintViolation {
+	v := createViolation(s.File, s.Import, suppressionRule, suppressionRule, cause, details)
+	v.Line = s.Line
+	v.Severity = SeverityWarning
+	return *v
+}
@@ -0,0 +1,188 @@
+package goverhaul
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSuppressions(t *testing.T) {
+	tests := map[string]struct {
+		source   string
+		expected []Suppression
+	}{
+		"file level": {
+			source: "//goverhaul:ignore cmd:allowed generated bindings\npackage main\n\nimport \"unsafe\"\n",
+			expected: []Suppression{
+				{File: "main.go", Line: 1, RuleID: "cmd:allowed", Reason: "generated bindings"},
+			},
+		},
+		"import doc and line comments": {
+			source: `package main
+
+import (
+	//goverhaul:ignore cmd:allowed needed for the syscall shim
+	"unsafe"
+	"os/exec" //goverhaul:ignore cmd
+	"fmt" // an unrelated comment
+)
+`,
+			expected: []Suppression{
+				{File: "main.go", Line: 4, Import: "unsafe", RuleID: "cmd:allowed", Reason: "needed for the syscall shim"},
+				{File: "main.go", Line: 6, Import: "os/exec", RuleID: "cmd"},
+			},
+		},
+		"single import declaration": {
+			source: "package main\n\n//goverhaul:ignore cmd:allowed legacy\nimport \"unsafe\"\n",
+			expected: []Suppression{
+				{File: "main.go", Line: 3, Import: "unsafe", RuleID: "cmd:allowed", Reason: "legacy"},
+			},
+		},
+		"similar directives are ignored": {
+			source:   "//goverhaul:ignored cmd reason\n// goverhaul:ignore cmd reason\npackage main\n",
+			expected: nil,
+		},
+		"missing rule id": {
+			source: "//goverhaul:ignore\npackage main\n",
+			expected: []Suppression{
+				{File: "main.go", Line: 1},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "main.go", test.source, parser.ImportsOnly|parser.ParseComments)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, fileSuppressions(fset, file))
+		})
+	}
+}
+
+func TestApplySuppressions(t *testing.T) {
+	violations := []LintViolation{
+		{File: "main.go", Line: 5, Import: "unsafe", Rule: "cmd", RuleID: "cmd:allowed"},
+		{File: "main.go", Line: 6, Import: "os/exec", Rule: "cmd", RuleID: "cmd:prohibited:os/exec"},
+	}
+
+	t.Run("import level suppression", func(t *testing.T) {
+		suppression := Suppression{File: "main.go", Line: 4, Import: "unsafe", RuleID: "cmd:allowed", Reason: "shim"}
+		remaining, applied := applySuppressions(violations, []Suppression{suppression})
+
+		assert.Equal(t, violations[1:], remaining)
+		assert.Equal(t, []Suppression{suppression}, applied)
+	})
+
+	t.Run("file level suppression by rule path", func(t *testing.T) {
+		suppression := Suppression{File: "main.go", Line: 1, RuleID: "cmd", Reason: "legacy tool"}
+		remaining, applied := applySuppressions(violations, []Suppression{suppression})
+
+		assert.Empty(t, remaining)
+		assert.Equal(t, []Suppression{suppression}, applied)
+	})
+
+	t.Run("suppression for another import", func(t *testing.T) {
+		suppression := Suppression{File: "main.go", Line: 7, Import: "fmt", RuleID: "cmd:allowed", Reason: "shim"}
+		remaining, applied := applySuppressions(violations, []Suppression{suppression})
+
+		require.Len(t, remaining, 3)
+		assert.Equal(t, violations, remaining[:2])
+		assert.Equal(t, suppressionRule, remaining[2].RuleID)
+		assert.Equal(t, "Suppression of cmd:allowed is unused", remaining[2].Cause)
+		assert.Equal(t, SeverityWarning, remaining[2].Severity)
+		assert.Equal(t, 7, remaining[2].Line)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("suppression without reason", func(t *testing.T) {
+		suppression := Suppression{File: "main.go", Line: 4, Import: "unsafe", RuleID: "cmd:allowed"}
+		remaining, applied := applySuppressions(violations, []Suppression{suppression})
+
+		require.Len(t, remaining, 3)
+		assert.Equal(t, violations, remaining[:2])
+		assert.Equal(t, "Suppression of cmd:allowed has no reason", remaining[2].Cause)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("suppression without rule id", func(t *testing.T) {
+		remaining, _ := applySuppressions(nil, []Suppression{{File: "main.go", Line: 1}})
+
+		require.Len(t, remaining, 1)
+		assert.Equal(t, "Suppression has no rule id", remaining[0].Cause)
+	})
+}
+
+func TestLintSuppressions(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "cmd/main.go", []byte(`package main
+
+import (
+	//goverhaul:ignore cmd:allowed needed for the syscall shim
+	"unsafe"
+	"os/exec"
+	"fmt" //goverhaul:ignore cmd:allowed
+)
+`), 0o644))
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules:   []Rule{{Path: "cmd", Allowed: []string{"os/exec"}}},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("cmd")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 2)
+
+	// The suppression without a reason does not hide the violation and is flagged
+	assert.Equal(t, "fmt", violations.Violations[0].Import)
+	assert.Equal(t, suppressionRule, violations.Violations[0].RuleID)
+	assert.Equal(t, SeverityWarning, violations.Violations[0].Severity)
+	assert.Equal(t, "fmt", violations.Violations[1].Import)
+	assert.Equal(t, "cmd:allowed", violations.Violations[1].RuleID)
+
+	assert.Equal(t, []Suppression{
+		{File: "cmd/main.go", Line: 4, Import: "unsafe", RuleID: "cmd:allowed", Reason: "needed for the syscall shim"},
+	}, linter.Summary().Suppressions)
+}
+
+func TestLintSuppressionsIncremental(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(memFs, "cmd/main.go", []byte(`package main
+
+import (
+	//goverhaul:ignore cmd:allowed needed for the syscall shim
+	"unsafe"
+	"os/exec"
+	"fmt" //goverhaul:ignore cmd:allowed
+)
+`), 0o644))
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules:       []Rule{{Path: "cmd", Allowed: []string{"os/exec"}}},
+	}
+	linter, err := NewLinter(cfg, nil, memFs)
+	require.NoError(t, err)
+
+	first, err := linter.Lint("cmd")
+	require.ErrorIs(t, err, ErrLint)
+	firstSummary := linter.Summary()
+	require.Len(t, firstSummary.Suppressions, 1)
+
+	// The second run serves the file from the cache, along with its suppressions
+	second, err := linter.Lint("cmd")
+	require.ErrorIs(t, err, ErrLint)
+	assert.Equal(t, 1, linter.Summary().FilesCached)
+	assert.Equal(t, firstSummary.Suppressions, linter.Summary().Suppressions)
+	assert.Len(t, second.Violations, len(first.Violations))
+}
@@ -0,0 +1,95 @@
+package goverhaul
+
+import "strings"
+
+// TestsMode controls how a rule treats _test.go files
+type TestsMode string
+
+const (
+	// TestsInclude checks test files like the other files of the package
+	TestsInclude TestsMode = "include"
+	// TestsExclude does not check test files
+	TestsExclude TestsMode = "exclude"
+	// TestsSeparate checks test files against the allowed and prohibited lists of the policy
+	TestsSeparate TestsMode = "separate"
+)
+
+// TestPackages selects the test files a tests policy applies to
+type TestPackages string
+
+const (
+	// TestPackagesAll selects every test file
+	TestPackagesAll TestPackages = "all"
+	// TestPackagesInternal selects the test files of the package itself (package x)
+	TestPackagesInternal TestPackages = "internal"
+	// TestPackagesExternal selects the test files of the external test package (package x_test)
+	TestPackagesExternal TestPackages = "external"
+)
+
+// TestsPolicy is how rules treat test files. It is set globally in Config.Tests,
+// and Rule.Tests replaces it for a single rule.
+type TestsPolicy struct {
+	Mode TestsMode `yaml:"mode" mapstructure:"mode"`
+	// Packages selects the test files the mode applies to (default: all),
+	// the other test files are checked like the other files of the package
+	Packages TestPackages `yaml:"packages" mapstructure:"packages"`
+	// Allowed, AllowedMode and Prohibited replace the lists of the rule in separate mode
+	Allowed     []string        `yaml:"allowed" mapstructure:"allowed"`
+	AllowedMode MatchMode       `yaml:"allowed_mode" mapstructure:"allowed_mode"`
+	Prohibited  []ProhibitedPkg `yaml:"prohibited" mapstructure:"prohibited"`
+}
+
+// validateTestsPolicy checks the mode and packages of a tests policy, if any
+func validateTestsPolicy(policy *TestsPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	switch policy.Mode {
+	case "", TestsInclude, TestsExclude, TestsSeparate:
+	default:
+		return WithDetails(NewConfigError("invalid tests mode "+string(policy.Mode), nil),
+			"tests mode must be one of: include, exclude, separate")
+	}
+
+	switch policy.Packages {
+	case "", TestPackagesAll, TestPackagesInternal, TestPackagesExternal:
+	default:
+		return WithDetails(NewConfigError("invalid tests packages "+string(policy.Packages), nil),
+			"tests packages must be one of: all, internal, external")
+	}
+
+	return nil
+}
+
+// covers reports whether the policy applies to test files of the given test package
+func (p *TestsPolicy) covers(pkg TestPackages) bool {
+	return p.Packages == "" || p.Packages == TestPackagesAll || p.Packages == pkg
+}
+
+// testPackageOf returns the test package a file belongs to: TestPackagesInternal for a _test.go
+// file of package x, TestPackagesExternal for one of package x_test, and "" for other files
+func testPackageOf(path, pkg string) TestPackages {
+	switch {
+	case !strings.HasSuffix(path, "_test.go"):
+		return ""
+	case strings.HasSuffix(pkg, "_test"):
+		return TestPackagesExternal
+	default:
+		return TestPackagesInternal
+	}
+}
+
+// testsRule returns the rule checking the test files of a rule in separate mode: the rule
+// with the lists of the policy, reported under the "<rule>:tests" name
+func testsRule(rule Rule, policy *TestsPolicy) Rule {
+	return Rule{
+		ID:          ruleName(rule) + ":tests",
+		Path:        rule.Path,
+		Severity:    rule.Severity,
+		Description: rule.Description,
+		Allowed:     policy.Allowed,
+		AllowedMode: policy.AllowedMode,
+		Prohibited:  policy.Prohibited,
+	}
+}
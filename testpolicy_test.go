@@ -0,0 +1,128 @@
+package goverhaul
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestPackageOf(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		pkg      string
+		expected TestPackages
+	}{
+		"regular file":           {path: "internal/domain/user.go", pkg: "domain", expected: ""},
+		"internal test package":  {path: "internal/domain/user_test.go", pkg: "domain", expected: TestPackagesInternal},
+		"external test package":  {path: "internal/domain/user_test.go", pkg: "domain_test", expected: TestPackagesExternal},
+		"test package name only": {path: "internal/domain/helpers.go", pkg: "domain_test", expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, testPackageOf(test.path, test.pkg))
+		})
+	}
+}
+
+func TestLintTestsPolicy(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"internal/domain/user.go":          "package domain\n\nimport \"github.com/acme/infra/db\"\n",
+		"internal/domain/user_test.go":     "package domain\n\nimport \"github.com/stretchr/testify/assert\"\n",
+		"internal/domain/external_test.go": "package domain_test\n\nimport \"github.com/acme/infra/fixtures\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+
+	tests := map[string]struct {
+		global   *TestsPolicy
+		rule     *TestsPolicy
+		expected map[string]string // file -> rule id of its violation
+	}{
+		"test files are checked like the others by default": {
+			expected: map[string]string{
+				"internal/domain/user.go":          "internal/domain:allowed",
+				"internal/domain/user_test.go":     "internal/domain:allowed",
+				"internal/domain/external_test.go": "internal/domain:allowed",
+			},
+		},
+		"exclude every test file": {
+			global: &TestsPolicy{Mode: TestsExclude},
+			expected: map[string]string{
+				"internal/domain/user.go": "internal/domain:allowed",
+			},
+		},
+		"exclude external test packages only": {
+			global: &TestsPolicy{Mode: TestsExclude, Packages: TestPackagesExternal},
+			expected: map[string]string{
+				"internal/domain/user.go":      "internal/domain:allowed",
+				"internal/domain/user_test.go": "internal/domain:allowed",
+			},
+		},
+		"rule policy replaces the global one": {
+			global: &TestsPolicy{Mode: TestsExclude},
+			rule:   &TestsPolicy{Mode: TestsInclude},
+			expected: map[string]string{
+				"internal/domain/user.go":          "internal/domain:allowed",
+				"internal/domain/user_test.go":     "internal/domain:allowed",
+				"internal/domain/external_test.go": "internal/domain:allowed",
+			},
+		},
+		"separate lists for test files": {
+			rule: &TestsPolicy{
+				Mode:       TestsSeparate,
+				Allowed:    []string{"@stdlib", "github.com/stretchr/testify/..."},
+				Prohibited: []ProhibitedPkg{{Name: "github.com/acme/infra/fixtures"}},
+			},
+			expected: map[string]string{
+				"internal/domain/user.go":          "internal/domain:allowed",
+				"internal/domain/external_test.go": "internal/domain:tests:prohibited:github.com/acme/infra/fixtures",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{
+				Modfile: "go.mod",
+				Tests:   test.global,
+				Rules: []Rule{
+					{Path: "internal/domain", Allowed: []string{"@stdlib"}, Tests: test.rule},
+				},
+			}
+			linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+			require.NoError(t, err)
+
+			violations, err := linter.Lint("internal")
+			require.ErrorIs(t, err, ErrLint)
+
+			actual := make(map[string]string)
+			for _, v := range violations.Violations {
+				actual[v.File] = v.RuleID
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestInvalidTestsPolicy(t *testing.T) {
+	tests := map[string]*TestsPolicy{
+		"unknown mode":          {Mode: "skip"},
+		"unknown packages":      {Mode: TestsExclude, Packages: "integration"},
+		"invalid separate list": {Mode: TestsSeparate, Allowed: []string{"@vendor"}},
+	}
+
+	for name, policy := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{Rules: []Rule{{Path: "internal", Tests: policy}}}
+			_, err := NewLinter(cfg, nil, afero.NewMemMapFs())
+			assert.ErrorIs(t, err, ErrConfig)
+		})
+	}
+}
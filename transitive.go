@@ -0,0 +1,160 @@
+package goverhaul
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// dependencyGraph holds the imports of the packages of the linted modules, read on demand
+// to follow imports transitively. It is safe for concurrent use.
+type dependencyGraph struct {
+	mu sync.Mutex
+	// Package path -> imports of its non-test files, nil when it is not a package of the modules
+	packages map[string][]importRef
+}
+
+// newDependencyGraph creates an empty dependency graph
+func newDependencyGraph() *dependencyGraph {
+	return &dependencyGraph{packages: make(map[string][]importRef)}
+}
+
+// importChain is a package reached from a file through the packages of its modules
+type importChain struct {
+	via  importRef   // The import of the file the chain starts with
+	path []importRef // The imports from the file to the reached package, via first
+}
+
+// importChains returns the packages a file reaches through the packages of its module and of the
+// workspace, along with the shortest import chain to each. The direct imports are not included.
+func (g *Goverhaul) importChains(imports []importRef, module goModule) []importChain {
+	seen := make(map[string]bool)
+	var queue, chains []importChain
+	for _, imp := range imports {
+		if !seen[imp.Path] {
+			seen[imp.Path] = true
+			queue = append(queue, importChain{via: imp, path: []importRef{imp}})
+		}
+	}
+
+	// Breadth-first, so the first chain reaching a package is the shortest
+	for len(queue) > 0 {
+		chain := queue[0]
+		queue = queue[1:]
+		if len(chain.path) > 1 {
+			chains = append(chains, chain)
+		}
+
+		last := chain.path[len(chain.path)-1]
+		for _, imp := range g.packageImports(last.Path, module) {
+			if seen[imp.Path] {
+				continue
+			}
+			seen[imp.Path] = true
+			queue = append(queue, importChain{via: chain.via, path: append(slices.Clip(chain.path), imp)})
+		}
+	}
+	return chains
+}
+
+// packageImports returns the imports of a package of the module or of the workspace,
+// and nil for the packages outside of them
+func (g *Goverhaul) packageImports(pkgPath string, module goModule) []importRef {
+	g.deps.mu.Lock()
+	imports, ok := g.deps.packages[pkgPath]
+	g.deps.mu.Unlock()
+	if ok {
+		return imports
+	}
+
+	imports = g.readPackageImports(pkgPath, module)
+	g.deps.mu.Lock()
+	g.deps.packages[pkgPath] = imports
+	g.deps.mu.Unlock()
+	return imports
+}
+
+// readPackageImports reads the imports of the non-test files of a package of the module or of the workspace.
+// As for the linted files, when build contexts are configured, only the files built in one of them are read.
+func (g *Goverhaul) readPackageImports(pkgPath string, module goModule) []importRef {
+	for _, m := range append([]goModule{module}, g.modules.workspace...) {
+		dir, ok := m.importDir(pkgPath)
+		// A nested module is not part of the module its directory is in
+		if !ok || g.modules.moduleOf(dir).path != m.path {
+			continue
+		}
+
+		entries, err := afero.ReadDir(g.fs, dir)
+		if err != nil {
+			return nil
+		}
+		seen := make(map[string]bool)
+		var imports []importRef
+		for _, entry := range entries {
+			name := entry.Name()
+			// Like the go tool, leave out the files starting with "." or "_"
+			if !isGoFileFs(entry) || strings.HasSuffix(name, "_test.go") ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				continue
+			}
+			path := JoinPaths(dir, name)
+			file, err := g.parseFile(path)
+			if err != nil {
+				g.logger.Debug("Could not parse file of an imported package", "path", path, "error", err)
+				continue
+			}
+			if len(g.cfg.BuildContexts) > 0 && len(g.fileContexts(path, file)) == 0 {
+				continue
+			}
+			resolved, _ := g.resolveImports(path, file.imports, m)
+			for _, imp := range resolved {
+				if !seen[imp.Path] {
+					seen[imp.Path] = true
+					imports = append(imports, imp)
+				}
+			}
+		}
+		return imports
+	}
+	return nil
+}
+
+// checkTransitive checks the packages a file reaches through other packages against the
+// transitive entries of a rule. Violations are reported on the import the chain starts with.
+func (g *Goverhaul) checkTransitive(path string, chains []importChain, compiled *compiledRule, moduleName string) []LintViolation {
+	violations := make([]LintViolation, 0)
+	matcher := newRuleMatcher(compiled, moduleName)
+	if g.modules != nil {
+		matcher.workspace = g.modules.workspacePaths()
+	}
+
+	normalizedPath := NormalizePath(path)
+	for _, chain := range chains {
+		reached := chain.path[len(chain.path)-1]
+		violation := matcher.checkTransitiveImport(reached, normalizedPath, g.logger)
+		if violation == nil {
+			continue
+		}
+		violation.Chain = importPaths(chain.path)
+		violation.Details += ", imported through " + strings.Join(violation.Chain[:len(violation.Chain)-1], " -> ")
+		violation.Line, violation.Column = chain.via.Pos.Line, chain.via.Pos.Column
+		violation.EndLine, violation.EndColumn = chain.via.End.Line, chain.via.End.Column
+		violations = append(violations, *violation)
+	}
+	return violations
+}
+
+// checkTransitiveImport checks a package reached through other packages against the
+// transitive prohibited entries and the transitive allow-list of the rule
+func (m *RuleMatcher) checkTransitiveImport(ref importRef, normalizedPath string, logger *slog.Logger) *LintViolation {
+	if prohibited, ok := m.matchProhibited(ref); ok && prohibited.Transitive {
+		return m.prohibitedViolation(logger, normalizedPath, ref.Path, prohibited)
+	}
+	if m.rule.AllowedTransitive && !m.isAllowed(ref) {
+		return m.allowedViolation(logger, normalizedPath, ref.Path)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package goverhaul
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTransitiveFs returns a module where internal/domain reaches database/sql through
+// internal/shared and internal/legacy, and through a longer chain via internal/store
+func newTransitiveFs(t *testing.T, domain string) afero.Fs {
+	t.Helper()
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"go.mod":                         "module example.com/app\n",
+		"internal/domain/user.go":        domain,
+		"internal/shared/shared.go":      "package shared\n\nimport (\n\t\"example.com/app/internal/store\"\n\t\"example.com/app/internal/legacy\"\n)\n",
+		"internal/shared/shared_test.go": "package shared\n\nimport \"example.com/app/internal/testdb\"\n",
+		"internal/store/store.go":        "package store\n\nimport \"example.com/app/internal/legacy\"\n",
+		"internal/legacy/legacy.go":      "package legacy\n\nimport \"database/sql\"\n",
+		"internal/testdb/testdb.go":      "package testdb\n\nimport \"database/sql/driver\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+	return memFs
+}
+
+func TestLintTransitive(t *testing.T) {
+	domain := "package domain\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/internal/shared\"\n)\n"
+
+	type found struct {
+		imp, rule string
+		line      int
+		chain     []string
+	}
+	tests := map[string]struct {
+		rule     Rule
+		expected []found
+	}{
+		"direct prohibited entries do not follow imports": {
+			rule: Rule{Path: "internal/domain", Prohibited: []ProhibitedPkg{{Name: "database/sql"}}},
+		},
+		"transitive prohibited entry reports the shortest chain": {
+			rule: Rule{Path: "internal/domain", Prohibited: []ProhibitedPkg{{Name: "database/sql", Transitive: true}}},
+			expected: []found{{
+				imp:   "database/sql",
+				rule:  "internal/domain:prohibited:database/sql",
+				line:  6,
+				chain: []string{"example.com/app/internal/shared", "example.com/app/internal/legacy", "database/sql"},
+			}},
+		},
+		"transitive prohibited pattern": {
+			rule: Rule{Path: "internal/domain", Prohibited: []ProhibitedPkg{{Name: "internal/store", Transitive: true}}},
+			expected: []found{{
+				imp:   "example.com/app/internal/store",
+				rule:  "internal/domain:prohibited:internal/store",
+				line:  6,
+				chain: []string{"example.com/app/internal/shared", "example.com/app/internal/store"},
+			}},
+		},
+		"transitive allow-list": {
+			rule: Rule{Path: "internal/domain", Allowed: []string{"@stdlib", "internal/shared"}, AllowedTransitive: true},
+			// Test files of the imported packages are not followed
+			expected: []found{
+				{
+					imp:   "example.com/app/internal/legacy",
+					rule:  "internal/domain:allowed",
+					line:  6,
+					chain: []string{"example.com/app/internal/shared", "example.com/app/internal/legacy"},
+				},
+				{
+					imp:   "example.com/app/internal/store",
+					rule:  "internal/domain:allowed",
+					line:  6,
+					chain: []string{"example.com/app/internal/shared", "example.com/app/internal/store"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			memFs := newTransitiveFs(t, domain)
+			cfg := Config{Modfile: "go.mod", Rules: []Rule{test.rule}}
+			linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+			require.NoError(t, err)
+
+			violations, err := linter.Lint("internal")
+			if len(test.expected) == 0 {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrLint)
+			}
+
+			var actual []found
+			for _, v := range violations.Violations {
+				assert.Equal(t, "internal/domain/user.go", v.File)
+				actual = append(actual, found{v.Import, v.RuleID, v.Line, v.Chain})
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestLintTransitiveSuppression(t *testing.T) {
+	domain := "package domain\n\nimport (\n" +
+		"\t//goverhaul:ignore internal/domain:prohibited:database/sql migrating away from legacy\n" +
+		"\t\"example.com/app/internal/shared\"\n)\n"
+	memFs := newTransitiveFs(t, domain)
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules:   []Rule{{Path: "internal/domain", Prohibited: []ProhibitedPkg{{Name: "database/sql", Transitive: true}}}},
+	}
+	linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	require.NoError(t, err)
+	assert.Empty(t, violations.Violations)
+	require.Len(t, linter.Summary().Suppressions, 1)
+}
+
+func TestLintTransitiveNotCached(t *testing.T) {
+	domain := "package domain\n\nimport \"example.com/app/internal/shared\"\n"
+	memFs := newTransitiveFs(t, domain)
+
+	cfg := Config{
+		Modfile:     "go.mod",
+		Incremental: true,
+		CacheFile:   "/cache",
+		Rules:       []Rule{{Path: "internal/domain", Prohibited: []ProhibitedPkg{{Name: "database/sql", Transitive: true}}}},
+	}
+	linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	require.ErrorIs(t, err, ErrLint)
+	require.Len(t, violations.Violations, 1)
+
+	// The violation goes away with the import of another package, although the file is unchanged
+	require.NoError(t, afero.WriteFile(memFs, "internal/legacy/legacy.go", []byte("package legacy\n"), 0o644))
+	violations, err = linter.Lint("internal")
+	require.NoError(t, err)
+	assert.Empty(t, violations.Violations)
+
+	status, _, err := linter.cache.HasEntry("internal/domain/user.go")
+	require.NoError(t, err)
+	assert.Equal(t, CacheMiss, status, "files checked by transitive rules are not cached")
+}
+
+func TestLintTransitiveBuildContexts(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"go.mod":                            "module example.com/app\n",
+		"internal/domain/user.go":           "package domain\n\nimport \"example.com/app/internal/shared\"\n",
+		"internal/shared/shared.go":         "package shared\n",
+		"internal/shared/shared_windows.go": "package shared\n\nimport \"database/sql\"\n",
+		"internal/shared/integration.go":    "//go:build integration\n\npackage shared\n\nimport \"database/sql\"\n",
+		"internal/shared/_old.go":           "package shared\n\nimport \"database/sql\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+
+	tests := map[string]struct {
+		contexts []BuildContext
+		expected []string // The contexts of the violation, nil when there is none
+	}{
+		"files not built in any context are not followed": {
+			contexts: []BuildContext{{GOOS: "linux", GOARCH: "amd64"}},
+		},
+		"files built for another platform are followed": {
+			contexts: []BuildContext{{GOOS: "linux", GOARCH: "amd64"}, {GOOS: "windows", GOARCH: "amd64"}},
+			expected: []string{"linux/amd64", "windows/amd64"},
+		},
+		"files built with a tag are followed": {
+			contexts: []BuildContext{{GOOS: "linux", GOARCH: "amd64", Tags: []string{"integration"}}},
+			expected: []string{"linux/amd64"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{
+				Modfile:       "go.mod",
+				BuildContexts: test.contexts,
+				Rules:         []Rule{{Path: "internal/domain", Prohibited: []ProhibitedPkg{{Name: "database/sql", Transitive: true}}}},
+			}
+			linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+			require.NoError(t, err)
+
+			violations, err := linter.Lint("internal")
+			if test.expected == nil {
+				require.NoError(t, err)
+				assert.Empty(t, violations.Violations)
+				return
+			}
+			require.ErrorIs(t, err, ErrLint)
+			require.Len(t, violations.Violations, 1)
+			assert.Equal(t, test.expected, violations.Violations[0].Contexts)
+		})
+	}
+}
+
+func TestLintTransitiveNestedModule(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"go.mod":                  "module example.com/app\n",
+		"internal/domain/user.go": "package domain\n\nimport \"example.com/app/tools/gen\"\n",
+		"tools/go.mod":            "module example.com/app/tools\n",
+		"tools/gen/gen.go":        "package gen\n\nimport \"database/sql\"\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(memFs, path, []byte(content), 0o644))
+	}
+
+	cfg := Config{
+		Modfile: "go.mod",
+		Rules:   []Rule{{Path: "internal/domain", Prohibited: []ProhibitedPkg{{Name: "database/sql", Transitive: true}}}},
+	}
+	linter, err := NewLinter(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)), memFs)
+	require.NoError(t, err)
+
+	violations, err := linter.Lint("internal")
+	require.NoError(t, err, "the packages of other modules are not followed")
+	assert.Empty(t, violations.Violations)
+}
+
+func TestLintViolationChainSuffix(t *testing.T) {
+	v := LintViolations{Violations: []LintViolation{{
+		File:   "internal/domain/user.go",
+		Line:   3,
+		Import: "database/sql",
+		Rule:   "internal/domain",
+		Chain:  []string{"example.com/app/internal/shared", "database/sql"},
+	}}}
+	assert.Contains(t, v.PrintByFile(), "Chain: example.com/app/internal/shared -> database/sql")
+	assert.Contains(t, v.PrintByRule(), "Chain: example.com/app/internal/shared -> database/sql")
+}
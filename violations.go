@@ -1,23 +1,116 @@
 package goverhaul
 
-import "fmt"
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Severity describes how serious a rule violation is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	// SeverityNone is only meaningful as a fail-on threshold: no violation reaches it
+	SeverityNone Severity = "none"
+)
+
+// ParseSeverity converts a string into a Severity, defaulting to SeverityError when empty
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(strings.ToLower(strings.TrimSpace(s))); sev {
+	case "":
+		return SeverityError, nil
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityNone:
+		return sev, nil
+	default:
+		return "", NewConfigError("invalid severity "+s, nil)
+	}
+}
+
+// rank orders severities from least to most serious. An empty severity counts as an error.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityNone:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// AtLeast reports whether the severity reaches the given threshold
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s.rank() >= threshold.rank()
+}
 
 // LintViolation represents a specific rule violation found during linting
 type LintViolation struct {
-	File    string `json:"file"`    // The file where the violation was found
-	Import  string `json:"import"`  // The import that violated the rule
-	Rule    string `json:"rule"`    // The rule that was violated
-	Cause   string `json:"cause"`   // The cause of the violation, if provided
-	Details string `json:"details"` // Additional details about the violation
-	Cached  bool   `json:"cached"`  // Whether the lint violation result was retrieved from the cache.
+	File        string   `json:"file"`                  // The file where the violation was found
+	Line        int      `json:"line"`                  // The line of the offending import (1-based, 0 if unknown)
+	Column      int      `json:"column"`                // The column of the offending import (1-based, 0 if unknown)
+	EndLine     int      `json:"end_line"`              // The line where the offending import ends
+	EndColumn   int      `json:"end_column"`            // The column just past the end of the offending import
+	Import      string   `json:"import"`                // The import that violated the rule
+	Rule        string   `json:"rule"`                  // The rule that was violated: its id, or its path
+	RuleID      string   `json:"rule_id"`               // The identifier of the violated check within the rule
+	Cause       string   `json:"cause"`                 // The cause of the violation, if provided
+	Details     string   `json:"details"`               // Additional details about the violation
+	Severity    Severity `json:"severity"`              // How serious the violation is
+	Description string   `json:"description,omitempty"` // The description of the violated rule, if provided
+	Contexts    []string `json:"contexts,omitempty"`    // The build contexts the violation was found in, if configured
+	Chain       []string `json:"chain,omitempty"`       // The imports leading to a package reached through other packages
+	Cached      bool     `json:"cached"`                // Whether the lint violation result was retrieved from the cache.
+}
+
+// Location returns the position of the violation as file:line:column,
+// omitting the parts that are unknown
+func (v *LintViolation) Location() string {
+	switch {
+	case v.Line > 0 && v.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", v.File, v.Line, v.Column)
+	case v.Line > 0:
+		return fmt.Sprintf("%s:%d", v.File, v.Line)
+	default:
+		return v.File
+	}
 }
 
 // Error implements the error interface
 func (v *LintViolation) Error() string {
 	if v.Cause != "" {
-		return fmt.Sprintf("Rule violation in %s: import %s is not allowed (%s)", v.File, v.Import, v.Cause)
+		return fmt.Sprintf("Rule violation in %s: import %s is not allowed (%s)", v.Location(), v.Import, v.Cause)
+	}
+	return fmt.Sprintf("Rule violation in %s: import %s is not allowed", v.Location(), v.Import)
+}
+
+// severityPrefix returns the severity label printed before violations that are not errors
+func (v *LintViolation) severityPrefix() string {
+	if v.Severity.rank() == SeverityError.rank() {
+		return ""
+	}
+	return "[" + string(v.Severity) + "] "
+}
+
+// contextsSuffix returns the build contexts printed after a violation, if any
+func (v *LintViolation) contextsSuffix() string {
+	if len(v.Contexts) == 0 {
+		return ""
 	}
-	return fmt.Sprintf("Rule violation in %s: import %s is not allowed", v.File, v.Import)
+	return ", Contexts: " + strings.Join(v.Contexts, ", ")
+}
+
+// chainSuffix returns the import chain printed after a transitive violation, if any
+func (v *LintViolation) chainSuffix() string {
+	if len(v.Chain) == 0 {
+		return ""
+	}
+	return ", Chain: " + strings.Join(v.Chain, " -> ")
 }
 
 // LintViolations is a collection of LintViolation errors
@@ -42,6 +135,35 @@ func (v *LintViolations) IsEmpty() bool {
 	return len(v.Violations) == 0
 }
 
+// Sort orders the violations by file, position, import and rule
+func (v *LintViolations) Sort() {
+	sortViolations(v.Violations)
+}
+
+// sortViolations orders violations by file, position, import and rule
+func sortViolations(violations []LintViolation) {
+	slices.SortStableFunc(violations, func(a, b LintViolation) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+			cmp.Compare(a.Import, b.Import),
+			cmp.Compare(a.Rule, b.Rule),
+		)
+	})
+}
+
+// CountAtLeast returns the number of violations whose severity reaches the threshold
+func (v *LintViolations) CountAtLeast(threshold Severity) int {
+	count := 0
+	for _, violation := range v.Violations {
+		if violation.Severity.AtLeast(threshold) {
+			count++
+		}
+	}
+	return count
+}
+
 // String implements the Stringer interface
 func (v *LintViolations) String() string {
 	return v.PrintByFile()
@@ -62,15 +184,18 @@ func (v *LintViolations) PrintByFile() string {
 	}
 
 	// Display violations for each file
-	for file, violations := range fileViolations {
+	for _, file := range sortedKeys(fileViolations) {
+		violations := fileViolations[file]
 		msg += fmt.Sprintf("File: %s (%d violations)\n", file, len(violations))
 
 		for _, violation := range violations {
+			msg += "  - " + violation.severityPrefix()
 			if violation.Cause != "" {
-				msg += fmt.Sprintf("  - Rule: %s, Import: %s, Cause: %s\n", violation.Rule, violation.Import, violation.Cause)
+				msg += fmt.Sprintf("Line: %d, Rule: %s, Import: %s, Cause: %s", violation.Line, violation.Rule, violation.Import, violation.Cause)
 			} else {
-				msg += fmt.Sprintf("  - Rule: %s, Import: %s\n", violation.Rule, violation.Import)
+				msg += fmt.Sprintf("Line: %d, Rule: %s, Import: %s", violation.Line, violation.Rule, violation.Import)
 			}
+			msg += violation.chainSuffix() + violation.contextsSuffix() + "\n"
 		}
 		msg += "\n"
 	}
@@ -93,18 +218,31 @@ func (v *LintViolations) PrintByRule() string {
 	}
 
 	// Display violations for each rule
-	for rule, violations := range ruleViolations {
+	for _, rule := range sortedKeys(ruleViolations) {
+		violations := ruleViolations[rule]
 		msg += fmt.Sprintf("Rule: %s (%d violations)\n", rule, len(violations))
 
 		for _, violation := range violations {
+			msg += "  - " + violation.severityPrefix()
 			if violation.Cause != "" {
-				msg += fmt.Sprintf("  - File: %s, Import: %s, Cause: %s\n", violation.File, violation.Import, violation.Cause)
+				msg += fmt.Sprintf("File: %s, Import: %s, Cause: %s", violation.Location(), violation.Import, violation.Cause)
 			} else {
-				msg += fmt.Sprintf("  - File: %s, Import: %s\n", violation.File, violation.Import)
+				msg += fmt.Sprintf("File: %s, Import: %s", violation.Location(), violation.Import)
 			}
+			msg += violation.chainSuffix() + violation.contextsSuffix() + "\n"
 		}
 		msg += "\n"
 	}
 
 	return msg
 }
+
+// sortedKeys returns the keys of the grouped violations in lexical order
+func sortedKeys(groups map[string][]LintViolation) []string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}